@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mantas9/listings/constants"
+	"mantas9/listings/formatter"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"mantas9/listings/orchestrator"
+	"mantas9/listings/writer"
+	"os"
+)
+
+// Fetches the activities of every collection and exports them in the specified format
+func (a *app) runActivity(ctx context.Context) int {
+	// Cancel the run after the global deadline
+	ctx, cancel := withTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	// Writer of the specified format
+	format, ok := writer.Lookup(a.cfg.Format)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", a.cfg.Format)
+		return constants.ExitFailure
+	}
+	aw, ok := format.New(writer.Options{Symbols: a.cfg.Symbols}).(writer.ActivityWriter)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Format %q cannot export activities\n", a.cfg.Format)
+		return constants.ExitFailure
+	}
+
+	// Fetch every collection, each with its own copy of the options
+	results := orchestrator.RunSymbols(ctx, a.cfg.Symbols, func(ctx context.Context, symbol string) ([]models.Activity, error) {
+		opts := a.cfg.Activities
+		opts.Symbol = symbol
+
+		return getActivities(ctx, a.client, opts)
+	}, orchestrator.RunOpts{Concurrency: a.cfg.Concurrency, FailFast: a.cfg.FailFast})
+
+	// Activities of every collection in command line order, including partially fetched collections
+	activities := []models.Activity{}
+	var failed []orchestrator.Result // Collections which failed to fetch
+	for _, res := range results {
+		activities = append(activities, res.Value...)
+
+		if res.Err != nil { // Record failure
+			failed = append(failed, orchestrator.Result{Symbol: res.Symbol, Err: res.Err})
+		} else if len(res.Value) == 0 { // Warn user about collections without matching activities
+			fmt.Fprintf(os.Stderr, "There are no matching activities for the collection %q according to your parameters.\n\n", res.Symbol)
+		}
+	}
+	a.reportFailures(ctx, failed)
+
+	// Nothing to write if every collection failed
+	if len(failed) == len(a.cfg.Symbols) && len(activities) == 0 {
+		return constants.ExitFailure
+	}
+
+	// Export activities in specified format, even when the run was interrupted
+	out, closeOut, path, err := a.openOutput(format)
+	if err == nil {
+		err = aw.WriteActivities(context.WithoutCancel(ctx), out, activities)
+
+		if closeErr := closeOut(); err == nil { // Report failed flushes
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in writing activities:\n%s\n", err)
+		return constants.ExitFailure
+	}
+
+	// Print success message
+	if a.cfg.Output != "-" {
+		fmt.Fprintf(os.Stderr, "Your selected collections' activities have been written to %s successfully.\n", path)
+	}
+
+	return exitCode(failed, len(a.cfg.Symbols), len(activities))
+}
+
+// Fetches and unmarshals the activities of a collection, keeping partial data of interrupted fetches
+func getActivities(ctx context.Context, client *httpfetcher.Client, opts httpfetcher.GetActivitiesOpts) ([]models.Activity, error) {
+	// Fetch HTTP data
+	data, err := client.GetActivities(ctx, opts)
+
+	// Error check, keeping partial data of interrupted fetches
+	if err != nil && data == nil {
+		return []models.Activity{}, err
+	}
+	fetchErr := err
+
+	// Unmarshal JSON data
+	res, err := formatter.UnmarshalActivitiesJSON(data, opts.Symbol)
+
+	if err != nil { // Error check
+		return []models.Activity{}, err
+	}
+
+	return res, fetchErr
+}
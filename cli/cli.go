@@ -0,0 +1,610 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mantas9/listings/enrich"
+	"mantas9/listings/formatter"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/orchestrator"
+	"mantas9/listings/writer"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Subcommand of the application
+type Command struct {
+	Name    string // Name given on the command line
+	Args    string // Positional arguments in usage text
+	Summary string // One line description
+}
+
+// Every subcommand, in the order they are listed in usage text
+var Commands = []Command{
+	{Name: "fetch", Args: "<collection1> <collection2> ... <collectionX>", Summary: "Fetch listings of collections and export them to a file"},
+	{Name: "stats", Args: "<collection1> <collection2> ... <collectionX>", Summary: "Fetch floor price, listed count, 24h average price and volume of collections"},
+	{Name: "activity", Args: "<collection1> <collection2> ... <collectionX>", Summary: "Fetch recent activities (listings, delistings, sales and bids) of collections and export them to a file"},
+	{Name: "watch", Args: "<collection1> <collection2> ... <collectionX>", Summary: "Fetch listings periodically and print new, removed and repriced listings"},
+	{Name: "diff", Args: "<old file> <new file>", Summary: "Compare two exported CSV/JSON/NDJSON files"},
+	{Name: "serve", Args: "", Summary: "Serve listings over HTTP as JSON"},
+}
+
+// Command used when no subcommand is given, so "listings <collections>" keeps working
+const DefaultCommand = "fetch"
+
+// Options shared by every subcommand
+type Global struct {
+	BaseURL    string        // API base URL
+	RPS        float64       // API requests per second
+	Retries    int           // Retries of failed requests
+	Timeout    time.Duration // Deadline of the run (per iteration for watch, per request for serve, 0 - none)
+	LogLevel   string        // Log level (debug, info, warn, error)
+	Verbose    bool          // Shorthand for debug log level
+	ConfigFile string        // Path to a JSON file with default parameter values
+}
+
+// Parsed command line parameters
+type Config struct {
+	Global
+	Command           string                        // Subcommand name
+	Symbols           []string                      // Collection symbols in command line order (fetch, stats, watch)
+	Files             []string                      // Files to compare (diff)
+	Listings          httpfetcher.GetListingsOpts   // Listing options shared by every collection (Symbol is unset)
+	Activities        httpfetcher.GetActivitiesOpts // Activity options shared by every collection (activity, Symbol is unset)
+	Types             string                        // Comma-separated activity types (activity)
+	JSON              bool                          // Export data in JSON format (same as Format "json")
+	Format            string                        // Export format (fetch, stats, activity)
+	Output            string                        // Export file path, "-" for stdout (fetch, stats, activity)
+	Compression       string                        // Compression codec of parquet exports (fetch, stats)
+	RowGroupSize      int                           // Maximum amount of rows per row group of parquet exports (fetch, stats)
+	Color             string                        // When to colour tables: auto (terminals only), always or never (fetch, stats)
+	Top               int                           // Amount of cheapest (or first with Sort) listings shown per collection in markdown reports, 0 for all (fetch)
+	Template          string                        // Path of a text/template rendering the export (fetch, stats)
+	TemplateString    string                        // Inline text/template rendering the export (fetch, stats)
+	Fields            string                        // Comma-separated optional listing fields, or "all" (fetch)
+	ListingFields     []string                      // Parsed Fields, in export order (fetch)
+	Sort              string                        // Order of exported listings across collections: price, rank or price-per-rank, "" for command line order (fetch)
+	Raw               bool                          // Export the original API object of each listing (fetch)
+	Enrich            bool                          // Merge token metadata into listings (fetch)
+	EnrichConcurrency int                           // Amount of tokens fetched at the same time while enriching (fetch)
+	CacheDir          string                        // Directory of cached token metadata, "" for the user cache directory (fetch)
+	NoCache           bool                          // Fetch every token instead of reading and writing the cache (fetch)
+	Concurrency       int                           // Amount of collections fetched at the same time
+	FailFast          bool                          // Stop the run on the first failed collection
+	Interval          time.Duration                 // Time between fetches (watch)
+	Count             int                           // Amount of fetches, 0 for no limit (watch)
+	Addr              string                        // Listen address (serve)
+}
+
+// Returns the configuration used when no parameters are given
+func defaultConfig(command string) Config {
+	// Stats are printed as a table unless another format is given
+	format := "csv"
+	if command == "stats" {
+		format = "table"
+	}
+
+	return Config{
+		Global: Global{
+			BaseURL:  httpfetcher.DefaultBaseURL,
+			RPS:      httpfetcher.DefaultRequestsPerSecond,
+			Retries:  httpfetcher.DefaultRetryPolicy.MaxAttempts - 1,
+			LogLevel: "warn",
+		},
+		Command:           command,
+		Format:            format,
+		Compression:       writer.DefaultCompression,
+		Color:             "auto",
+		Concurrency:       orchestrator.DefaultConcurrency,
+		EnrichConcurrency: enrich.DefaultConcurrency,
+		Interval:          time.Minute,
+		Addr:              "localhost:8080",
+	}
+}
+
+// Defines the parameters shared by every subcommand
+func globalFlags(f *flagSet, cfg *Global) {
+	f.stringVar(&cfg.BaseURL, flagDef{name: "base-url", env: "LISTINGS_BASE_URL", placeholder: "url",
+		usage: "Sets the API base URL"})
+	f.float64Var(&cfg.RPS, flagDef{name: "rps", env: "LISTINGS_RPS", placeholder: "number",
+		usage: "Sets the maximum amount of API requests per second, negative to disable"})
+	f.intVar(&cfg.Retries, flagDef{name: "retries", env: "LISTINGS_RETRIES", placeholder: "integer",
+		usage: "Sets how many times failed requests are retried with exponential backoff"})
+	f.durationVar(&cfg.Timeout, flagDef{name: "timeout", short: "t", env: "LISTINGS_TIMEOUT", placeholder: "duration",
+		usage: "Sets a deadline for the whole run (per fetch for watch, per request for serve), e.g. 30s or 2m"})
+	f.stringVar(&cfg.LogLevel, flagDef{name: "log-level", env: "LISTINGS_LOG_LEVEL", placeholder: "level",
+		usage: "Sets the level of logs written to stderr: debug, info, warn or error"})
+	f.boolVar(&cfg.Verbose, flagDef{name: "verbose", short: "v", env: "LISTINGS_VERBOSE",
+		usage: "Log throttling decisions and other details to stderr (same as --log-level debug)"})
+	f.stringVar(&cfg.ConfigFile, flagDef{name: "config", env: "LISTINGS_CONFIG", placeholder: "file",
+		usage: "Reads default parameter values from a JSON file, e.g. {\"limit\": 10, \"base-url\": \"...\"}"})
+}
+
+// Defines the listing query parameters
+func listingFlags(f *flagSet, cfg *Config) {
+	f.int64Var(&cfg.Listings.Limit, flagDef{name: "limit", short: "l", env: "LISTINGS_LIMIT", placeholder: "integer",
+		usage: "Sets a limit to the amount of listings to fetch for each collection (pages through results if over 100)"})
+	f.float64Var(&cfg.Listings.MinPrice, flagDef{name: "min-price", env: "LISTINGS_MIN_PRICE", placeholder: "number",
+		usage: "Filters listings with a minimum price"})
+	f.float64Var(&cfg.Listings.MaxPrice, flagDef{name: "max-price", env: "LISTINGS_MAX_PRICE", placeholder: "number",
+		usage: "Filters listings with a maximum price"})
+	f.int64Var(&cfg.Listings.MinRank, flagDef{name: "min-rank", env: "LISTINGS_MIN_RANK", placeholder: "integer",
+		usage: "Keeps listings with a rarity rank of at least N, dropping unranked ones (Magic Eden rank, else Moonrank, else HowRare)"})
+	f.int64Var(&cfg.Listings.MaxRank, flagDef{name: "max-rank", env: "LISTINGS_MAX_RANK", placeholder: "integer",
+		usage: "Keeps listings with a rarity rank of at most N (1 is the rarest), dropping unranked ones"})
+	f.boolVar(&cfg.Listings.All, flagDef{name: "all", short: "a", env: "LISTINGS_ALL",
+		usage: "Fetch every listing of each collection (capped by --limit if set)"})
+	f.boolVar(&cfg.Listings.Desc, flagDef{name: "desc", short: "d", env: "LISTINGS_DESC",
+		usage: "Sort by price in descending order (default - ascending)"})
+	concurrencyFlag(f, cfg)
+}
+
+// Defines the amount of collections fetched at the same time
+func concurrencyFlag(f *flagSet, cfg *Config) {
+	f.intVar(&cfg.Concurrency, flagDef{name: "concurrency", short: "c", env: "LISTINGS_CONCURRENCY", placeholder: "integer",
+		usage: "Sets how many collections are fetched at the same time"})
+}
+
+// Defines the export parameters, base is the default file name without extension
+func exportFlags(f *flagSet, cfg *Config, base string) {
+	output := "Sets the export file, - for stdout (default - " + base + ".<format>, stdout for tables and templates)"
+	if !slices.Contains(exportFormats(cfg.Command), "table") {
+		output = "Sets the export file, - for stdout (default - " + base + ".<format>)"
+	}
+
+	f.stringVar(&cfg.Format, flagDef{name: "format", short: "f", env: "LISTINGS_FORMAT", placeholder: "format",
+		usage: "Sets the export format (" + strings.Join(exportFormats(cfg.Command), ", ") + "), inferred from the --output extension if not given"})
+	f.stringVar(&cfg.Output, flagDef{name: "output", short: "o", env: "LISTINGS_OUTPUT", placeholder: "path|-",
+		usage: output})
+	f.boolVar(&cfg.JSON, flagDef{name: "json", short: "j", env: "LISTINGS_JSON",
+		usage: "Export data in JSON format (same as --format json)"})
+}
+
+// Defines the parameters of parquet exports and tables
+func formatOptionFlags(f *flagSet, cfg *Config) {
+	f.stringVar(&cfg.Compression, flagDef{name: "compression", env: "LISTINGS_COMPRESSION", placeholder: "codec",
+		usage: "Sets the compression of parquet exports (" + strings.Join(writer.Compressions(), ", ") + ")"})
+	f.intVar(&cfg.RowGroupSize, flagDef{name: "row-group-size", env: "LISTINGS_ROW_GROUP_SIZE", placeholder: "integer",
+		usage: "Sets the maximum amount of rows per row group of parquet exports (default - unlimited)"})
+	f.stringVar(&cfg.Color, flagDef{name: "color", env: "LISTINGS_COLOR", placeholder: "when",
+		usage: "Colours tables: auto (when stdout is a terminal and $NO_COLOR is unset), always or never"})
+}
+
+// Defines the template parameters of exports, what is rendered by the template
+func templateFlags(f *flagSet, cfg *Config, what string) {
+	f.stringVar(&cfg.Template, flagDef{name: "template", env: "LISTINGS_TEMPLATE", placeholder: "file",
+		usage: "Renders " + what + " through a Go text/template file (implies --format template)"})
+	f.stringVar(&cfg.TemplateString, flagDef{name: "template-string", env: "LISTINGS_TEMPLATE_STRING", placeholder: "template",
+		usage: "Renders " + what + " through an inline Go text/template (implies --format template)"})
+}
+
+// Returns the names of the formats a subcommand can export to
+func exportFormats(command string) []string {
+	switch command {
+	case "stats":
+		return writer.StatsNames()
+	case "activity":
+		return writer.ActivityNames()
+	}
+
+	return writer.Names()
+}
+
+// Defines every parameter of a subcommand on a new flag set, writing parsed values to cfg
+func newCommandFlags(command string, cfg *Config) *flagSet {
+	f := newFlagSet("listings " + command)
+
+	// Subcommand parameters
+	switch command {
+	case "fetch":
+		listingFlags(f, cfg)
+		exportFlags(f, cfg, "listings")
+		formatOptionFlags(f, cfg)
+		f.intVar(&cfg.Top, flagDef{name: "top", env: "LISTINGS_TOP", placeholder: "integer",
+			usage: "Shows only the N cheapest listings of each collection in markdown reports, or the first N in the --sort order (default - all)"})
+		templateFlags(f, cfg, "listings")
+		f.stringVar(&cfg.Sort, flagDef{name: "sort", env: "LISTINGS_SORT", placeholder: "order",
+			usage: "Sorts listings across collections: price (cheapest first), rank (rarest first) or price-per-rank (lowest price × rank first) (default - command line order)"})
+		f.stringVar(&cfg.Fields, flagDef{name: "fields", env: "LISTINGS_FIELDS", placeholder: "fields",
+			usage: "Adds the given comma-separated API fields to csv, json, ndjson and template exports (" + strings.Join(formatter.FieldNames(), ", ") + " or all)"})
+		f.boolVar(&cfg.Raw, flagDef{name: "raw", env: "LISTINGS_RAW",
+			usage: "Exports the original API object of each listing, unchanged (json and ndjson only)"})
+		f.boolVar(&cfg.Enrich, flagDef{name: "enrich", env: "LISTINGS_ENRICH",
+			usage: "Merges the name, image and attributes of each listed token into csv, json, ndjson and template exports (one request per token, cached on disk)"})
+		f.intVar(&cfg.EnrichConcurrency, flagDef{name: "enrich-concurrency", env: "LISTINGS_ENRICH_CONCURRENCY", placeholder: "integer",
+			usage: "Sets how many tokens are fetched at the same time with --enrich"})
+		f.stringVar(&cfg.CacheDir, flagDef{name: "cache-dir", env: "LISTINGS_CACHE_DIR", placeholder: "dir",
+			usage: "Sets the directory of cached token metadata (default - listings/tokens in the user cache directory)"})
+		f.boolVar(&cfg.NoCache, flagDef{name: "no-cache", env: "LISTINGS_NO_CACHE",
+			usage: "Fetch every token with --enrich instead of reading and writing the cache"})
+		f.boolVar(&cfg.FailFast, flagDef{name: "fail-fast", env: "LISTINGS_FAIL_FAST",
+			usage: "Stop the whole run as soon as one collection fails"})
+	case "stats":
+		concurrencyFlag(f, cfg)
+		exportFlags(f, cfg, "stats")
+		formatOptionFlags(f, cfg)
+		templateFlags(f, cfg, "stats (.Stats)")
+		f.boolVar(&cfg.FailFast, flagDef{name: "fail-fast", env: "LISTINGS_FAIL_FAST",
+			usage: "Stop the whole run as soon as one collection fails"})
+	case "activity":
+		f.int64Var(&cfg.Activities.Limit, flagDef{name: "limit", short: "l", env: "LISTINGS_LIMIT", placeholder: "integer",
+			usage: "Sets a limit to the amount of activities to fetch for each collection (pages through results if over 100)"})
+		f.boolVar(&cfg.Activities.All, flagDef{name: "all", short: "a", env: "LISTINGS_ALL",
+			usage: "Fetch the whole activity history of each collection (capped by --limit if set)"})
+		f.stringVar(&cfg.Types, flagDef{name: "type", env: "LISTINGS_TYPE", placeholder: "types",
+			usage: "Keeps only activities of the given comma-separated types (" + strings.Join(httpfetcher.ActivityTypes, ", ") + ")"})
+		concurrencyFlag(f, cfg)
+		exportFlags(f, cfg, "activities")
+		f.boolVar(&cfg.FailFast, flagDef{name: "fail-fast", env: "LISTINGS_FAIL_FAST",
+			usage: "Stop the whole run as soon as one collection fails"})
+	case "watch":
+		listingFlags(f, cfg)
+		f.durationVar(&cfg.Interval, flagDef{name: "interval", short: "i", env: "LISTINGS_INTERVAL", placeholder: "duration",
+			usage: "Sets the time between fetches"})
+		f.intVar(&cfg.Count, flagDef{name: "count", short: "n", env: "LISTINGS_COUNT", placeholder: "integer",
+			usage: "Stops after the given amount of fetches (default - until Ctrl-C)"})
+	case "diff":
+		f.boolVar(&cfg.JSON, flagDef{name: "json", short: "j", env: "LISTINGS_JSON",
+			usage: "Print changes in JSON format"})
+	case "serve":
+		listingFlags(f, cfg)
+		f.stringVar(&cfg.Addr, flagDef{name: "addr", env: "LISTINGS_ADDR", placeholder: "host:port",
+			usage: "Sets the address the HTTP server listens on"})
+	}
+
+	// Shared parameters
+	globalFlags(f, &cfg.Global)
+
+	return f
+}
+
+// Returns the subcommand with the given name
+func LookupCommand(name string) (Command, bool) {
+	i := slices.IndexFunc(Commands, func(cmd Command) bool { return cmd.Name == name })
+	if i < 0 {
+		return Command{}, false
+	}
+
+	return Commands[i], true
+}
+
+// Parses and validates command line arguments (excluding the program call).
+// The first argument selects the subcommand; without one, arguments are parsed as "fetch".
+// Parameters can be placed before, between or after positional arguments, and given as --name value or --name=value.
+// Parameters which are not given fall back to their environment variable (read with getenv) and then to the config file.
+// If help was requested, ErrHelp is returned along with a config holding the subcommand
+func Parse(args []string, getenv func(string) string) (Config, error) {
+	// Select subcommand
+	command := DefaultCommand
+	if len(args) > 0 {
+		if args[0] == "help" { // "listings help [command]"
+			cfg := defaultConfig("")
+			if len(args) > 1 {
+				if _, ok := LookupCommand(args[1]); !ok {
+					return Config{}, fmt.Errorf("unknown command %q", args[1])
+				}
+				cfg.Command = args[1]
+			}
+			return cfg, ErrHelp
+		}
+
+		if _, ok := LookupCommand(args[0]); ok {
+			command = args[0]
+			args = args[1:]
+		}
+	}
+
+	cfg := defaultConfig(command)
+
+	// Parse parameters and positional arguments
+	flags := newCommandFlags(command, &cfg)
+	positional, err := flags.parse(args, getenv, func() (map[string]string, error) {
+		return loadConfigFile(cfg.ConfigFile)
+	})
+
+	if errors.Is(err, ErrHelp) { // Keep the subcommand for its help message
+		return cfg, err
+	}
+	if err != nil { // Error check
+		return Config{}, err
+	}
+
+	// Positional arguments
+	if command == "diff" {
+		cfg.Files = positional
+	} else {
+		cfg.Symbols = positional
+	}
+
+	// Activity types, matched case-insensitively
+	if command == "activity" && cfg.Types != "" {
+		for _, name := range strings.Split(cfg.Types, ",") {
+			i := slices.IndexFunc(httpfetcher.ActivityTypes, func(t string) bool { return strings.EqualFold(t, strings.TrimSpace(name)) })
+			if i < 0 {
+				return Config{}, fmt.Errorf("--type must be a comma-separated list of %s, got %q", strings.Join(httpfetcher.ActivityTypes, ", "), name)
+			}
+			if !slices.Contains(cfg.Activities.Types, httpfetcher.ActivityTypes[i]) {
+				cfg.Activities.Types = append(cfg.Activities.Types, httpfetcher.ActivityTypes[i])
+			}
+		}
+	}
+
+	// Optional listing fields, matched case-insensitively
+	if command == "fetch" && cfg.Fields != "" {
+		names := formatter.FieldNames()
+		for _, name := range strings.Split(cfg.Fields, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), "all") {
+				cfg.ListingFields = names
+				break
+			}
+
+			i := slices.IndexFunc(names, func(n string) bool { return strings.EqualFold(n, strings.TrimSpace(name)) })
+			if i < 0 {
+				return Config{}, fmt.Errorf("--fields must be a comma-separated list of %s or all, got %q", strings.Join(names, ", "), name)
+			}
+			if !slices.Contains(cfg.ListingFields, names[i]) {
+				cfg.ListingFields = append(cfg.ListingFields, names[i])
+			}
+		}
+
+		// Fields are exported in the order of formatter.ListingFields
+		slices.SortFunc(cfg.ListingFields, func(a, b string) int { return slices.Index(names, a) - slices.Index(names, b) })
+	}
+
+	// Export options
+	if command == "fetch" || command == "stats" || command == "activity" {
+		// A lone "-" among collections is shorthand for --output -
+		if i := slices.Index(cfg.Symbols, "-"); i >= 0 {
+			if cfg.Output != "" && cfg.Output != "-" {
+				return Config{}, fmt.Errorf("cannot export to both stdout (-) and --output %q", cfg.Output)
+			}
+			cfg.Output = "-"
+			cfg.Symbols = slices.Delete(cfg.Symbols, i, i+1)
+		}
+
+		// --json is shorthand for --format json
+		if cfg.JSON {
+			if flags.given("format") && cfg.Format != "json" {
+				return Config{}, fmt.Errorf("--json conflicts with --format %s", cfg.Format)
+			}
+			cfg.Format = "json"
+		}
+
+		// --template and --template-string imply --format template
+		if cfg.Template != "" || cfg.TemplateString != "" {
+			if cfg.Template != "" && cfg.TemplateString != "" {
+				return Config{}, errors.New("--template conflicts with --template-string")
+			}
+			if (flags.given("format") || cfg.JSON) && !strings.EqualFold(cfg.Format, "template") {
+				return Config{}, fmt.Errorf("--template conflicts with --format %s", cfg.Format)
+			}
+			cfg.Format = "template"
+		}
+
+		// Infer the format from the --output extension unless given, printing a table to terminals
+		if !cfg.JSON && !flags.given("format") && cfg.Format != "template" {
+			if format, ok := writer.ForPath(cfg.Output); ok {
+				cfg.Format = format.Name
+			} else if cfg.Output == "-" && stdoutIsTerminal() && slices.Contains(exportFormats(command), "table") {
+				cfg.Format = "table"
+			}
+		}
+		cfg.Format = strings.ToLower(cfg.Format)
+
+		// Tables and templates are printed to stdout unless --output is given
+		if format, ok := writer.Lookup(cfg.Format); ok && format.Stdout && cfg.Output == "" {
+			cfg.Output = "-"
+		}
+		cfg.Color = strings.ToLower(cfg.Color)
+	}
+
+	// Validate
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	// Listings filtered or sorted by rank export it, in formats with optional fields
+	rankSelected := cfg.Listings.MinRank > 0 || cfg.Listings.MaxRank > 0 || cfg.Sort == "rank" || cfg.Sort == "price-per-rank"
+	if command == "fetch" && rankSelected && !cfg.Raw && slices.Contains(fieldFormats, cfg.Format) && !slices.Contains(cfg.ListingFields, "rank") {
+		names := formatter.FieldNames()
+		cfg.ListingFields = append(cfg.ListingFields, "rank")
+		slices.SortFunc(cfg.ListingFields, func(a, b string) int { return slices.Index(names, a) - slices.Index(names, b) })
+	}
+
+	return cfg, nil
+}
+
+// Formats exporting optional listing fields and token metadata
+var fieldFormats = []string{"csv", "json", "ndjson", "template"}
+
+// Checks parameter values and positional arguments of the subcommand
+func (cfg Config) validate() error {
+	// Shared options
+	if cfg.BaseURL == "" {
+		return errors.New("--base-url must not be empty")
+	}
+	if cfg.Retries < 0 {
+		return fmt.Errorf("--retries must not be negative, got %d", cfg.Retries)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %v", cfg.Timeout)
+	}
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(cfg.LogLevel)) {
+		return fmt.Errorf("--log-level must be one of debug, info, warn or error, got %q", cfg.LogLevel)
+	}
+	if cfg.Concurrency < 1 { // Every command fetching collections
+		return fmt.Errorf("--concurrency must be at least 1, got %d", cfg.Concurrency)
+	}
+
+	switch cfg.Command {
+	case "fetch", "watch":
+		if err := cfg.validateListings(); err != nil {
+			return err
+		}
+		if err := cfg.validateSymbols(); err != nil {
+			return err
+		}
+		if cfg.Sort != "" && !slices.Contains(formatter.SortOrders, cfg.Sort) {
+			return fmt.Errorf("--sort must be one of %s, got %q", strings.Join(formatter.SortOrders, ", "), cfg.Sort)
+		}
+		if cfg.EnrichConcurrency < 1 {
+			return fmt.Errorf("--enrich-concurrency must be at least 1, got %d", cfg.EnrichConcurrency)
+		}
+	case "activity":
+		if cfg.Activities.Limit < 0 {
+			return fmt.Errorf("--limit must not be negative, got %d", cfg.Activities.Limit)
+		}
+		if err := cfg.validateSymbols(); err != nil {
+			return err
+		}
+	case "stats":
+		if err := cfg.validateSymbols(); err != nil {
+			return err
+		}
+	case "serve":
+		if err := cfg.validateListings(); err != nil {
+			return err
+		}
+		if len(cfg.Symbols) > 0 {
+			return fmt.Errorf("serve takes no collections, got %q", cfg.Symbols)
+		}
+		if cfg.Addr == "" {
+			return errors.New("--addr must not be empty")
+		}
+	case "diff":
+		if len(cfg.Files) != 2 {
+			return fmt.Errorf("diff takes exactly 2 files, got %d", len(cfg.Files))
+		}
+	}
+
+	// Export options
+	if cfg.Command == "fetch" || cfg.Command == "stats" || cfg.Command == "activity" {
+		format, ok := writer.Lookup(cfg.Format)
+		if !ok || !slices.Contains(exportFormats(cfg.Command), format.Name) {
+			return fmt.Errorf("--format must be one of %s, got %q", strings.Join(exportFormats(cfg.Command), ", "), cfg.Format)
+		}
+		if format.FileOnly && cfg.Output == "-" {
+			return fmt.Errorf("--format %s can only be written to a file, not stdout", cfg.Format)
+		}
+		if !slices.Contains(writer.Compressions(), strings.ToLower(cfg.Compression)) {
+			return fmt.Errorf("--compression must be one of %s, got %q", strings.Join(writer.Compressions(), ", "), cfg.Compression)
+		}
+		if !slices.Contains([]string{"auto", "always", "never"}, cfg.Color) {
+			return fmt.Errorf("--color must be auto, always or never, got %q", cfg.Color)
+		}
+		if len(cfg.ListingFields) > 0 && !slices.Contains(fieldFormats, format.Name) {
+			return fmt.Errorf("--fields is only supported by csv, json, ndjson and template exports, not --format %s", format.Name)
+		}
+		if cfg.Enrich && !slices.Contains(fieldFormats, format.Name) {
+			return fmt.Errorf("--enrich is only supported by csv, json, ndjson and template exports, not --format %s", format.Name)
+		}
+		if cfg.Raw && format.Name != "json" && format.Name != "ndjson" {
+			return fmt.Errorf("--raw needs --format json or ndjson, got %s", format.Name)
+		}
+		if cfg.Raw && (cfg.Fields != "" || cfg.Enrich) {
+			return errors.New("--raw exports every API field as is and conflicts with --fields and --enrich")
+		}
+		if format.Name == "template" && cfg.Template == "" && cfg.TemplateString == "" {
+			return errors.New("--format template needs --template or --template-string")
+		}
+		if cfg.Top < 0 {
+			return fmt.Errorf("--top must not be negative, got %d", cfg.Top)
+		}
+		if cfg.RowGroupSize < 0 {
+			return fmt.Errorf("--row-group-size must not be negative, got %d", cfg.RowGroupSize)
+		}
+	}
+
+	// Watch options
+	if cfg.Command == "watch" {
+		if cfg.Interval <= 0 {
+			return fmt.Errorf("--interval must be positive, got %v", cfg.Interval)
+		}
+		if cfg.Count < 0 {
+			return fmt.Errorf("--count must not be negative, got %d", cfg.Count)
+		}
+	}
+
+	return nil
+}
+
+// Checks listing query parameters
+func (cfg Config) validateListings() error {
+	if cfg.Listings.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative, got %d", cfg.Listings.Limit)
+	}
+	if cfg.Listings.MinPrice < 0 {
+		return fmt.Errorf("--min-price must not be negative, got %v", cfg.Listings.MinPrice)
+	}
+	if cfg.Listings.MaxPrice < 0 {
+		return fmt.Errorf("--max-price must not be negative, got %v", cfg.Listings.MaxPrice)
+	}
+	if cfg.Listings.MaxPrice > 0 && cfg.Listings.MinPrice > cfg.Listings.MaxPrice {
+		return fmt.Errorf("--min-price (%v) must not be greater than --max-price (%v)", cfg.Listings.MinPrice, cfg.Listings.MaxPrice)
+	}
+	if cfg.Listings.MinRank < 0 {
+		return fmt.Errorf("--min-rank must not be negative, got %d", cfg.Listings.MinRank)
+	}
+	if cfg.Listings.MaxRank < 0 {
+		return fmt.Errorf("--max-rank must not be negative, got %d", cfg.Listings.MaxRank)
+	}
+	if cfg.Listings.MaxRank > 0 && cfg.Listings.MinRank > cfg.Listings.MaxRank {
+		return fmt.Errorf("--min-rank (%d) must not be greater than --max-rank (%d)", cfg.Listings.MinRank, cfg.Listings.MaxRank)
+	}
+	return nil
+}
+
+// Checks collection symbols
+func (cfg Config) validateSymbols() error {
+	if len(cfg.Symbols) == 0 {
+		return errors.New("no collections given")
+	}
+
+	seen := map[string]bool{} // Symbols already given
+	for _, symbol := range cfg.Symbols {
+		if symbol == "" {
+			return errors.New("collection symbol must not be empty")
+		}
+		if seen[symbol] {
+			return fmt.Errorf("collection %q was given more than once", symbol)
+		}
+		seen[symbol] = true
+	}
+
+	return nil
+}
+
+// Writes usage text of a subcommand, generated from its parameter definitions.
+// An empty command writes the overview of every subcommand
+func Usage(w io.Writer, command string) {
+	cmd, ok := LookupCommand(command)
+
+	// Overview
+	if !ok {
+		fmt.Fprintln(w, "Usage: ./listings <command> <parameters> <arguments>")
+		fmt.Fprintln(w, "       ./listings <parameters> <collection1> <collection2> ... <collectionX>  (same as fetch)")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Commands:")
+		for _, cmd := range Commands {
+			fmt.Fprintf(w, "  %-10s%s\n", cmd.Name, cmd.Summary)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Run \"./listings help <command>\" or \"./listings <command> --help\" for the parameters of a command.")
+		return
+	}
+
+	// Subcommand
+	fmt.Fprintf(w, "Usage: ./listings %s <parameters> %s\n", cmd.Name, cmd.Args)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, cmd.Summary+".")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Parameters can be placed before or after arguments, and given as --name value or --name=value.")
+	fmt.Fprintln(w, "Parameters which are not given are read from the environment variable in brackets, then from --config.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Possible parameters:")
+
+	cfg := defaultConfig(cmd.Name)
+	newCommandFlags(cmd.Name, &cfg).printDefaults(w)
+}
@@ -0,0 +1,451 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParse calls Parse with flags in every supported position and form, validating the parsed config
+func TestParse(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		terminal bool                  // Stdout is a terminal
+		check    func(cfg Config) bool // Validates the parsed config
+	}{
+		{
+			name:  "Collections only",
+			args:  []string{"degods", "y00ts"},
+			check: func(cfg Config) bool { return reflect.DeepEqual(cfg.Symbols, []string{"degods", "y00ts"}) && !cfg.JSON },
+		},
+		{
+			name:  "Flags after collections",
+			args:  []string{"degods", "--json"},
+			check: func(cfg Config) bool { return reflect.DeepEqual(cfg.Symbols, []string{"degods"}) && cfg.JSON },
+		},
+		{
+			name: "Flags between collections",
+			args: []string{"--limit", "5", "degods", "--desc", "y00ts", "--min-price=1.5"},
+			check: func(cfg Config) bool {
+				return reflect.DeepEqual(cfg.Symbols, []string{"degods", "y00ts"}) &&
+					cfg.Listings == httpfetcher.GetListingsOpts{Limit: 5, Desc: true, MinPrice: 1.5}
+			},
+		},
+		{
+			name: "Short aliases",
+			args: []string{"-l", "10", "-j", "-c=2", "-t", "30s", "degods"},
+			check: func(cfg Config) bool {
+				return cfg.Listings.Limit == 10 && cfg.JSON && cfg.Concurrency == 2 && cfg.Timeout == 30*time.Second
+			},
+		},
+		{
+			name: "Double dash ends parameters",
+			args: []string{"--json", "--", "degods", "--desc"},
+			check: func(cfg Config) bool {
+				return reflect.DeepEqual(cfg.Symbols, []string{"degods", "--desc"}) && !cfg.Listings.Desc
+			},
+		},
+		{
+			name: "Environment fallback",
+			args: []string{"degods"},
+			env:  map[string]string{"LISTINGS_LIMIT": "7", "LISTINGS_JSON": "true", "LISTINGS_BASE_URL": "http://localhost"},
+			check: func(cfg Config) bool {
+				return cfg.Listings.Limit == 7 && cfg.JSON && cfg.BaseURL == "http://localhost"
+			},
+		},
+		{
+			name:  "Flag overrides environment",
+			args:  []string{"-l", "3", "degods"},
+			env:   map[string]string{"LISTINGS_LIMIT": "7"},
+			check: func(cfg Config) bool { return cfg.Listings.Limit == 3 },
+		},
+		{
+			name: "Defaults",
+			args: []string{"degods"},
+			check: func(cfg Config) bool {
+				return cfg.BaseURL == httpfetcher.DefaultBaseURL && cfg.Retries == 3 && cfg.Concurrency == 4 && cfg.RPS == 2 &&
+					cfg.Format == "csv" && cfg.Output == ""
+			},
+		},
+		{
+			name:  "Format and output",
+			args:  []string{"--format", "JSON", "-o", "out.json", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "json" && cfg.Output == "out.json" },
+		},
+		{
+			name: "Parquet options",
+			args: []string{"-o", "listings.parquet", "--compression", "zstd", "--row-group-size", "1000", "degods"},
+			check: func(cfg Config) bool {
+				return cfg.Format == "parquet" && cfg.Compression == "zstd" && cfg.RowGroupSize == 1000
+			},
+		},
+		{
+			name:  "Markdown top listings",
+			args:  []string{"-o", "floor.md", "--top", "5", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "markdown" && cfg.Top == 5 },
+		},
+		{
+			name: "Template",
+			args: []string{"--template", "inserts.sql.tmpl", "degods"},
+			check: func(cfg Config) bool {
+				return cfg.Format == "template" && cfg.Output == "-" && cfg.Template == "inserts.sql.tmpl"
+			},
+		},
+		{
+			name:  "Template string to file",
+			args:  []string{"--template-string", "{{.Symbols}}", "-o", "out.json", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "template" && cfg.Output == "out.json" },
+		},
+		{
+			name:  "Format inferred from output",
+			args:  []string{"-o", "out/listings.JSON", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "json" },
+		},
+		{
+			name:  "Format overrides output extension",
+			args:  []string{"-o", "listings.json", "--format", "csv", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "csv" },
+		},
+		{
+			name:  "Unknown output extension",
+			args:  []string{"-o", "listings.dat", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "csv" },
+		},
+		{
+			name:     "Table on terminals",
+			args:     []string{"-", "degods"},
+			terminal: true,
+			check:    func(cfg Config) bool { return cfg.Format == "table" && cfg.Output == "-" },
+		},
+		{
+			name:  "CSV when piped",
+			args:  []string{"-", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "csv" && cfg.Output == "-" },
+		},
+		{
+			name:     "File export on terminals",
+			args:     []string{"degods"},
+			terminal: true,
+			check:    func(cfg Config) bool { return cfg.Format == "csv" && cfg.Output == "" },
+		},
+		{
+			name:  "Table printed to stdout",
+			args:  []string{"--format", "table", "--color", "NEVER", "degods"},
+			check: func(cfg Config) bool { return cfg.Output == "-" && cfg.Color == "never" },
+		},
+		{
+			name:  "JSON shorthand",
+			args:  []string{"--json", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "json" },
+		},
+		{
+			name:  "Stats table printed to stdout",
+			args:  []string{"stats", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "table" && cfg.Output == "-" },
+		},
+		{
+			name:  "Stats JSON shorthand",
+			args:  []string{"stats", "--json", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "json" && cfg.Output == "" },
+		},
+		{
+			name: "Rank filters and sort",
+			args: []string{"--min-rank", "10", "--max-rank=500", "--sort", "price-per-rank", "degods", "y00ts"},
+			check: func(cfg Config) bool {
+				return cfg.Listings == httpfetcher.GetListingsOpts{MinRank: 10, MaxRank: 500} && cfg.Sort == "price-per-rank"
+			},
+		},
+		{
+			name: "Fields in export order",
+			args: []string{"--fields", "Rarity, pdaAddress,rarity", "degods"},
+			check: func(cfg Config) bool {
+				return reflect.DeepEqual(cfg.ListingFields, []string{"pdaAddress", "rarity"})
+			},
+		},
+		{
+			name: "All fields",
+			args: []string{"--fields", "all", "--json", "degods"},
+			check: func(cfg Config) bool {
+				return len(cfg.ListingFields) == 12 && cfg.ListingFields[0] == "pdaAddress"
+			},
+		},
+		{
+			name:  "Raw",
+			args:  []string{"--raw", "-o", "raw.ndjson", "degods"},
+			check: func(cfg Config) bool { return cfg.Raw && cfg.Format == "ndjson" && cfg.ListingFields == nil },
+		},
+		{ // Rank is added in export order
+			name: "Rank filter exports rank",
+			args: []string{"--max-rank", "500", "--fields", "rarity,img", "degods"},
+			check: func(cfg Config) bool {
+				return reflect.DeepEqual(cfg.ListingFields, []string{"rarity", "rank", "img"})
+			},
+		},
+		{
+			name:  "Rank sort exports rank",
+			args:  []string{"--sort", "price-per-rank", "--json", "degods"},
+			check: func(cfg Config) bool { return reflect.DeepEqual(cfg.ListingFields, []string{"rank"}) },
+		},
+		{ // Reports have no optional fields
+			name:  "Rank sort in a report",
+			args:  []string{"--sort", "rank", "--format", "markdown", "degods"},
+			check: func(cfg Config) bool { return cfg.ListingFields == nil },
+		},
+		{
+			name:  "Price sort",
+			args:  []string{"--sort", "price", "degods"},
+			check: func(cfg Config) bool { return cfg.ListingFields == nil },
+		},
+		{
+			name: "Enrich",
+			args: []string{"--enrich", "--enrich-concurrency", "16", "--no-cache", "degods"},
+			check: func(cfg Config) bool {
+				return cfg.Enrich && cfg.EnrichConcurrency == 16 && cfg.NoCache && cfg.CacheDir == ""
+			},
+		},
+		{
+			name: "Enrich defaults",
+			args: []string{"degods"},
+			env:  map[string]string{"LISTINGS_CACHE_DIR": "/tmp/tokens"},
+			check: func(cfg Config) bool {
+				return !cfg.Enrich && cfg.EnrichConcurrency == 8 && !cfg.NoCache && cfg.CacheDir == "/tmp/tokens"
+			},
+		},
+		{
+			name:  "Stats format inferred from output",
+			args:  []string{"stats", "-o", "floors.xlsx", "degods"},
+			check: func(cfg Config) bool { return cfg.Format == "xlsx" && cfg.Output == "floors.xlsx" },
+		},
+		{
+			name: "Activity types and paging",
+			args: []string{"activity", "--type", "BUYNOW, bid,buynow", "-l", "500", "degods"},
+			check: func(cfg Config) bool {
+				return reflect.DeepEqual(cfg.Activities, httpfetcher.GetActivitiesOpts{Limit: 500, Types: []string{"buyNow", "bid"}}) && cfg.Format == "csv"
+			},
+		},
+		{
+			name:     "Activity to a terminal stays CSV",
+			args:     []string{"activity", "-", "degods"},
+			terminal: true,
+			check:    func(cfg Config) bool { return cfg.Format == "csv" && cfg.Output == "-" },
+		},
+		{
+			name: "Stdout shorthand",
+			args: []string{"--format", "json", "-", "degods"},
+			check: func(cfg Config) bool {
+				return cfg.Output == "-" && reflect.DeepEqual(cfg.Symbols, []string{"degods"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTerminal(t, tt.terminal)
+
+			cfg, err := Parse(tt.args, func(key string) string { return tt.env[key] })
+
+			if err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !tt.check(cfg) {
+				t.Errorf("Unexpected config: %+v", cfg)
+			}
+		})
+	}
+}
+
+// TestParseErrors calls Parse with invalid arguments and validates the returned errors
+func TestParseErrors(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string // Substring of the wanted error
+	}{
+		{name: "Invalid limit", args: []string{"--limit", "abc", "degods"}, wantErr: `invalid value "abc" for --limit`},
+		{name: "Negative limit", args: []string{"--limit=-5", "degods"}, wantErr: "--limit must not be negative"},
+		{name: "Min over max price", args: []string{"--min-price", "5", "--max-price", "2", "degods"}, wantErr: "must not be greater than --max-price"},
+		{name: "Duplicate symbol", args: []string{"degods", "y00ts", "degods"}, wantErr: `"degods" was given more than once`},
+		{name: "Empty symbol", args: []string{""}, wantErr: "must not be empty"},
+		{name: "No collections", args: []string{"--json"}, wantErr: "no collections given"},
+		{name: "Unknown parameter", args: []string{"--jsn", "degods"}, wantErr: "unknown parameter --jsn"},
+		{name: "Missing value", args: []string{"degods", "--limit"}, wantErr: "missing value for --limit"},
+		{name: "Invalid concurrency", args: []string{"-c", "0", "degods"}, wantErr: "--concurrency must be at least 1"},
+		{name: "File only format to stdout", args: []string{"--format", "sqlite", "-", "degods"}, wantErr: "--format sqlite can only be written to a file"},
+		{name: "Negative top", args: []string{"--top", "-3", "degods"}, wantErr: "--top must not be negative"},
+		{name: "Template and template string", args: []string{"--template", "a.tmpl", "--template-string", "x", "degods"}, wantErr: "--template conflicts with --template-string"},
+		{name: "Template and format", args: []string{"--template", "a.tmpl", "--json", "degods"}, wantErr: "--template conflicts with --format json"},
+		{name: "Template format without template", args: []string{"--format", "template", "degods"}, wantErr: "--format template needs --template"},
+		{name: "Unknown color", args: []string{"--color", "sometimes", "degods"}, wantErr: "--color must be auto, always or never"},
+		{name: "Unknown compression", args: []string{"--compression", "rar", "degods"}, wantErr: "--compression must be one of"},
+		{name: "Negative rank", args: []string{"--max-rank", "-1", "degods"}, wantErr: "--max-rank must not be negative"},
+		{name: "Min over max rank", args: []string{"--min-rank", "100", "--max-rank", "10", "degods"}, wantErr: "--min-rank (100) must not be greater than --max-rank (10)"},
+		{name: "Unknown sort", args: []string{"--sort", "seller", "degods"}, wantErr: "--sort must be one of price, rank, price-per-rank"},
+		{name: "Unknown field", args: []string{"--fields", "pdaAddress,owner", "degods"}, wantErr: `--fields must be a comma-separated list of pdaAddress, `},
+		{name: "Fields in a table", args: []string{"--fields", "rarity", "--format", "table", "degods"}, wantErr: "--fields is only supported by csv, json, ndjson and template exports"},
+		{name: "Enrich in a markdown report", args: []string{"--enrich", "--format", "markdown", "degods"}, wantErr: "--enrich is only supported by csv, json, ndjson and template exports"},
+		{name: "Raw CSV", args: []string{"--raw", "degods"}, wantErr: "--raw needs --format json or ndjson"},
+		{name: "Raw with fields", args: []string{"--raw", "--json", "--fields", "rarity", "degods"}, wantErr: "conflicts with --fields and --enrich"},
+		{name: "Invalid enrich concurrency", args: []string{"--enrich", "--enrich-concurrency", "0", "degods"}, wantErr: "--enrich-concurrency must be at least 1"},
+		{name: "Enrich outside fetch", args: []string{"stats", "--enrich", "degods"}, wantErr: "unknown parameter --enrich"},
+		{name: "Negative row group size", args: []string{"--row-group-size", "-1", "degods"}, wantErr: "--row-group-size must not be negative"},
+		{name: "Unknown format", args: []string{"--format", "xml", "degods"}, wantErr: "--format must be one of csv, "},
+		{name: "Conflicting format", args: []string{"--json", "--format", "csv", "degods"}, wantErr: "--json conflicts with --format csv"},
+		{name: "Conflicting output", args: []string{"-o", "a.csv", "-", "degods"}, wantErr: "cannot export to both stdout"},
+		{name: "Invalid environment", args: []string{"degods"}, env: map[string]string{"LISTINGS_TIMEOUT": "soon"}, wantErr: "$LISTINGS_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.args, func(key string) string { return tt.env[key] })
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Got error %v, wanted error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Help
+	for _, arg := range []string{"-h", "--help"} {
+		if _, err := Parse([]string{"degods", arg}, func(string) string { return "" }); !errors.Is(err, ErrHelp) {
+			t.Errorf("%s: got error %v, wanted ErrHelp", arg, err)
+		}
+	}
+}
+
+// TestParseCommands calls Parse with every subcommand and validates command selection and positional arguments
+func TestParseCommands(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name        string
+		args        []string
+		wantCommand string
+		wantSymbols []string
+		wantFiles   []string
+		wantErr     string // Substring of the wanted error, empty if none
+	}{
+		{name: "Fetch alias", args: []string{"degods", "--json"}, wantCommand: "fetch", wantSymbols: []string{"degods"}},
+		{name: "Fetch alias with leading flag", args: []string{"--json", "degods"}, wantCommand: "fetch", wantSymbols: []string{"degods"}},
+		{name: "Fetch", args: []string{"fetch", "degods", "y00ts"}, wantCommand: "fetch", wantSymbols: []string{"degods", "y00ts"}},
+		{name: "Collection named like a command", args: []string{"fetch", "stats"}, wantCommand: "fetch", wantSymbols: []string{"stats"}},
+		{name: "Stats", args: []string{"stats", "-c", "2", "degods"}, wantCommand: "stats", wantSymbols: []string{"degods"}},
+		{name: "Watch", args: []string{"watch", "degods", "--interval=30s", "-n", "3"}, wantCommand: "watch", wantSymbols: []string{"degods"}},
+		{name: "Diff", args: []string{"diff", "old.csv", "new.csv"}, wantCommand: "diff", wantFiles: []string{"old.csv", "new.csv"}},
+		{name: "Serve", args: []string{"serve", "--addr", ":9000"}, wantCommand: "serve"},
+		{name: "Diff needs two files", args: []string{"diff", "old.csv"}, wantErr: "exactly 2 files"},
+		{name: "Serve takes no collections", args: []string{"serve", "degods"}, wantErr: "serve takes no collections"},
+		{name: "Invalid interval", args: []string{"watch", "degods", "--interval", "0s"}, wantErr: "--interval must be positive"},
+		{name: "Parameter of another command", args: []string{"diff", "--limit", "5", "a", "b"}, wantErr: "unknown parameter --limit"},
+		{name: "Activity", args: []string{"activity", "--type", "buyNow", "degods"}, wantCommand: "activity", wantSymbols: []string{"degods"}},
+		{name: "Unknown activity type", args: []string{"activity", "--type", "buyNow,sale", "degods"}, wantErr: `--type must be a comma-separated list of list, delist, buyNow, bid, got "sale"`},
+		{name: "Activity format", args: []string{"activity", "-o", "a.xlsx", "degods"}, wantErr: `--format must be one of csv, json, ndjson, got "xlsx"`},
+		{name: "Activity negative limit", args: []string{"activity", "-l", "-1", "degods"}, wantErr: "--limit must not be negative"},
+		{name: "Stats take no listing query", args: []string{"stats", "--limit", "5", "degods"}, wantErr: "unknown parameter --limit"},
+		{name: "Stats format without stats", args: []string{"stats", "--format", "html", "degods"}, wantErr: "--format must be one of csv, json, "},
+		{name: "Invalid log level", args: []string{"degods", "--log-level", "loud"}, wantErr: "--log-level must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse(tt.args, func(string) string { return "" })
+
+			// Error scenarios
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Got error %v, wanted error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Validate command and positional arguments
+			if cfg.Command != tt.wantCommand {
+				t.Errorf("Got command %q, wanted %q", cfg.Command, tt.wantCommand)
+			}
+			if len(cfg.Symbols) > 0 || len(tt.wantSymbols) > 0 {
+				if !reflect.DeepEqual(cfg.Symbols, tt.wantSymbols) {
+					t.Errorf("Got symbols %q, wanted %q", cfg.Symbols, tt.wantSymbols)
+				}
+			}
+			if len(cfg.Files) > 0 || len(tt.wantFiles) > 0 {
+				if !reflect.DeepEqual(cfg.Files, tt.wantFiles) {
+					t.Errorf("Got files %q, wanted %q", cfg.Files, tt.wantFiles)
+				}
+			}
+		})
+	}
+
+	// Help of a subcommand
+	for _, args := range [][]string{{"help", "watch"}, {"watch", "--help"}} {
+		cfg, err := Parse(args, func(string) string { return "" })
+		if !errors.Is(err, ErrHelp) || cfg.Command != "watch" {
+			t.Errorf("%q: got (%q, %v), wanted help of watch", args, cfg.Command, err)
+		}
+	}
+}
+
+// TestParseConfigFile calls Parse with a config file and validates precedence of flags, environment and config values
+func TestParseConfigFile(t *testing.T) {
+	// Config file
+	path := filepath.Join(t.TempDir(), "listings.json")
+	if err := os.WriteFile(path, []byte(`{"limit": 10, "json": true, "base-url": "http://config", "interval": "5s"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"LISTINGS_BASE_URL": "http://env"}
+	cfg, err := Parse([]string{"--config", path, "--limit", "3", "degods"}, func(key string) string { return env[key] })
+
+	if err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Flag beats config, environment beats config, config beats default
+	if cfg.Listings.Limit != 3 || cfg.BaseURL != "http://env" || !cfg.JSON {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	// Unknown parameters are rejected
+	if err := os.WriteFile(path, []byte(`{"limt": 10}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse([]string{"--config", path, "degods"}, func(string) string { return "" }); err == nil || !strings.Contains(err.Error(), `"limt"`) {
+		t.Errorf("Got error %v, wanted unknown parameter error", err)
+	}
+}
+
+// TestUsage checks that the usage text of every subcommand lists its parameter definitions
+func TestUsage(t *testing.T) {
+	for _, cmd := range Commands {
+		var buf bytes.Buffer
+		Usage(&buf, cmd.Name)
+
+		cfg := defaultConfig(cmd.Name)
+		for _, def := range newCommandFlags(cmd.Name, &cfg).defs {
+			if !strings.Contains(buf.String(), "--"+def.name) {
+				t.Errorf("%s usage is missing --%s", cmd.Name, def.name)
+			}
+		}
+	}
+
+	// Overview lists every subcommand
+	var buf bytes.Buffer
+	Usage(&buf, "")
+	for _, cmd := range Commands {
+		if !strings.Contains(buf.String(), cmd.Name) {
+			t.Errorf("Overview is missing %s", cmd.Name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Reads a JSON config file of parameter names and values, e.g. {"limit": 10, "json": true}.
+// Returns no values if path is empty
+func loadConfigFile(path string) (map[string]string, error) {
+	if path == "" { // No config file
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil { // Error check
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	// Unmarshal into generic values
+	raw := map[string]any{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse config file %s: %w", path, err)
+	}
+
+	// Known parameters of every subcommand
+	known := map[string]bool{}
+	for _, cmd := range Commands {
+		cfg := defaultConfig(cmd.Name)
+		for _, def := range newCommandFlags(cmd.Name, &cfg).defs {
+			known[def.name] = true
+		}
+	}
+
+	// Convert values to flag strings
+	values := map[string]string{}
+	for name, value := range raw {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %q in config file %s", name, path)
+		}
+
+		switch v := value.(type) {
+		case string:
+			values[name] = v
+		case float64:
+			values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("invalid value for %q in config file %s: must be a string, number or boolean", name, path)
+		}
+	}
+
+	return values, nil
+}
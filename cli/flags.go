@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Returned by Parse when help was requested with -h or --help
+var ErrHelp = flag.ErrHelp
+
+// Definition of a single flag, used to generate usage text
+type flagDef struct {
+	name        string // Long name, given as --name
+	short       string // Short alias, given as -short (empty if none)
+	env         string // Environment variable used when the flag is not given (empty if none)
+	placeholder string // Value placeholder in usage text (empty for boolean flags)
+	usage       string // Description
+}
+
+// Set of flags which can be given anywhere between positional arguments
+type flagSet struct {
+	fs   *flag.FlagSet // Underlying standard library flag set
+	defs []*flagDef    // Flag definitions in registration order
+}
+
+// Creates an empty flag set
+func newFlagSet(name string) *flagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Errors are returned and printed by the caller
+	fs.Usage = func() {}
+
+	return &flagSet{fs: fs}
+}
+
+// Registers a flag under its long name and short alias, using register to define each name
+func (f *flagSet) add(def flagDef, register func(name string)) {
+	register(def.name)
+	if def.short != "" {
+		register(def.short)
+	}
+
+	f.defs = append(f.defs, &def)
+}
+
+// Registers a string flag, defaulting to the current value of p
+func (f *flagSet) stringVar(p *string, def flagDef) {
+	value := *p
+	f.add(def, func(name string) { f.fs.StringVar(p, name, value, def.usage) })
+}
+
+// Registers an integer flag, defaulting to the current value of p
+func (f *flagSet) intVar(p *int, def flagDef) {
+	value := *p
+	f.add(def, func(name string) { f.fs.IntVar(p, name, value, def.usage) })
+}
+
+// Registers a 64-bit integer flag, defaulting to the current value of p
+func (f *flagSet) int64Var(p *int64, def flagDef) {
+	value := *p
+	f.add(def, func(name string) { f.fs.Int64Var(p, name, value, def.usage) })
+}
+
+// Registers a number flag, defaulting to the current value of p
+func (f *flagSet) float64Var(p *float64, def flagDef) {
+	value := *p
+	f.add(def, func(name string) { f.fs.Float64Var(p, name, value, def.usage) })
+}
+
+// Registers a boolean flag, defaulting to the current value of p
+func (f *flagSet) boolVar(p *bool, def flagDef) {
+	value := *p
+	def.placeholder = "" // Boolean flags take no value
+	f.add(def, func(name string) { f.fs.BoolVar(p, name, value, def.usage) })
+}
+
+// Registers a duration flag, defaulting to the current value of p
+func (f *flagSet) durationVar(p *time.Duration, def flagDef) {
+	value := *p
+	f.add(def, func(name string) { f.fs.DurationVar(p, name, value, def.usage) })
+}
+
+// Parses flags placed anywhere in args and returns the positional arguments in order.
+// Everything after "--" is treated as positional. Flags which were not given fall back to their
+// environment variable (read with getenv), and then to the values returned by config
+func (f *flagSet) parse(args []string, getenv func(string) string, config func() (map[string]string, error)) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := f.fs.Parse(args); err != nil {
+			return nil, flagError(err)
+		}
+
+		rest := f.fs.Args()
+
+		// Everything after "--" is positional
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+
+		if len(rest) == 0 { // Done
+			break
+		}
+
+		// Take the positional argument and keep parsing flags after it
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	// Flags given on the command line or through the environment
+	given := map[string]bool{}
+	f.fs.Visit(func(fl *flag.Flag) {
+		given[fl.Name] = true
+	})
+	isGiven := func(def *flagDef) bool {
+		return given[def.name] || (def.short != "" && given[def.short])
+	}
+
+	// Environment variable fallbacks
+	for _, def := range f.defs {
+		if def.env == "" || isGiven(def) {
+			continue
+		}
+
+		value := getenv(def.env)
+		if value == "" { // Not set
+			continue
+		}
+
+		if err := f.fs.Set(def.name, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for $%s", value, def.env)
+		}
+		given[def.name] = true
+	}
+
+	// Config file fallbacks
+	if config == nil {
+		return positional, nil
+	}
+
+	values, err := config()
+
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	for _, def := range f.defs {
+		value, ok := values[def.name]
+		if !ok || isGiven(def) {
+			continue
+		}
+
+		if err := f.fs.Set(def.name, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %q in config file", value, def.name)
+		}
+	}
+
+	return positional, nil
+}
+
+// Reports whether a flag was given on the command line
+func (f *flagSet) given(name string) bool {
+	given := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		given = given || fl.Name == name
+	})
+
+	return given
+}
+
+// Writes a table of every flag with its alias, value placeholder, environment variable and default value
+func (f *flagSet) printDefaults(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, def := range f.defs {
+		// Names
+		names := "    --" + def.name
+		if def.short != "" {
+			names = "-" + def.short + ", --" + def.name
+		}
+		if def.placeholder != "" {
+			names += " <" + def.placeholder + ">"
+		}
+
+		// Description with default value and environment variable
+		usage := def.usage
+		if fl := f.fs.Lookup(def.name); fl != nil && fl.DefValue != "" && fl.DefValue != "0" && fl.DefValue != "false" && fl.DefValue != "0s" {
+			usage += fmt.Sprintf(" (default - %s)", fl.DefValue)
+		}
+		if def.env != "" {
+			usage += fmt.Sprintf(" [$%s]", def.env)
+		}
+
+		fmt.Fprintf(table, "  %s\t%s\n", names, usage)
+	}
+
+	// Help is handled by the flag package
+	fmt.Fprintf(table, "  %s\t%s\n", "-h, --help", "Print this message")
+
+	table.Flush()
+}
+
+// Rewrites standard library flag errors to refer to parameters as --name
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return ErrHelp
+	}
+
+	msg := err.Error()
+	msg = strings.Replace(msg, "flag provided but not defined: -", "unknown parameter --", 1)
+	msg = strings.Replace(msg, "flag needs an argument: -", "missing value for --", 1)
+	msg = strings.Replace(msg, " for flag -", " for --", 1)
+	msg = strings.TrimSuffix(msg, ": parse error")
+
+	return errors.New(msg)
+}
@@ -0,0 +1,26 @@
+package cli
+
+import "os"
+
+// Reports whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Reports whether stdout is a terminal (replaced in tests)
+var stdoutIsTerminal = func() bool { return IsTerminal(os.Stdout) }
+
+// Reports whether terminal output should be coloured, according to --color and the NO_COLOR environment variable
+func (cfg Config) ColorOutput(getenv func(string) string) bool {
+	switch cfg.Color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	// Auto
+	return cfg.Output == "-" && stdoutIsTerminal() && getenv("NO_COLOR") == ""
+}
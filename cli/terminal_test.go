@@ -0,0 +1,40 @@
+package cli
+
+import "testing"
+
+// Replaces stdout terminal detection for the duration of a test
+func setTerminal(t *testing.T, terminal bool) {
+	original := stdoutIsTerminal
+	stdoutIsTerminal = func() bool { return terminal }
+
+	t.Cleanup(func() { stdoutIsTerminal = original })
+}
+
+// TestColorOutput calls ColorOutput with every --color value, output and environment
+func TestColorOutput(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name     string
+		cfg      Config
+		terminal bool
+		env      map[string]string
+		want     bool
+	}{
+		{name: "Auto, terminal", cfg: Config{Color: "auto", Output: "-"}, terminal: true, want: true},
+		{name: "Auto, piped", cfg: Config{Color: "auto", Output: "-"}, terminal: false, want: false},
+		{name: "Auto, file", cfg: Config{Color: "auto", Output: "listings.txt"}, terminal: true, want: false},
+		{name: "Auto, NO_COLOR", cfg: Config{Color: "auto", Output: "-"}, terminal: true, env: map[string]string{"NO_COLOR": "1"}, want: false},
+		{name: "Always, piped", cfg: Config{Color: "always", Output: "-"}, terminal: false, want: true},
+		{name: "Never, terminal", cfg: Config{Color: "never", Output: "-"}, terminal: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTerminal(t, tt.terminal)
+
+			if got := tt.cfg.ColorOutput(func(key string) string { return tt.env[key] }); got != tt.want {
+				t.Errorf("Got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,9 @@
 package constants
 
-import (
-	"fmt"
-	"os"
+// Exit codes of the application
+const (
+	ExitSuccess = 0  // Every collection was fetched
+	ExitFailure = 1  // No collection was fetched and nothing was exported
+	ExitPartial = 2  // Some collections failed or were interrupted, what was fetched was exported
+	ExitUsage   = 64 // Invalid parameters
 )
-
-const helpMessage = `Usage: ./listings <parameters> <collection1> <collection2> ... <collectionX>` + "\nPossible parameters:\n\t--limit <integer>\tSets a limit to the amount of listings to fetch for each collection\n\t--min-price <number>\tFilters listings with a minimum price\n\t--max-price <number>\tFilters listings with a maximum price\n\t--desc\t\t\tSort by price in Descending order (default - by price in Ascending order)\n\t--json\t Export data in JSON format"
-
-// Prints a help message to terminal and exits the application
-func HelpMessage() {
-	fmt.Println(helpMessage)
-	os.Exit(0)
-}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mantas9/listings/constants"
+	"mantas9/listings/diff"
+	"mantas9/listings/formatter"
+	"mantas9/listings/models"
+	"mantas9/listings/writer"
+	"os"
+)
+
+// Compares two exported files and prints added, removed and repriced listings
+func (a *app) runDiff(ctx context.Context) int {
+	// Read both exports
+	before, err := readListingsFile(a.cfg.Files[0])
+	if err != nil { // Error check
+		fmt.Fprintf(os.Stderr, "Error in reading %s:\n%s\n", a.cfg.Files[0], err)
+		return constants.ExitFailure
+	}
+
+	after, err := readListingsFile(a.cfg.Files[1])
+	if err != nil { // Error check
+		fmt.Fprintf(os.Stderr, "Error in reading %s:\n%s\n", a.cfg.Files[1], err)
+		return constants.ExitFailure
+	}
+
+	changes := diff.Compare(before, after)
+
+	// Print changes in specified format
+	if a.cfg.JSON { // JSON
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(changes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in writing changes:\n%s\n", err)
+			return constants.ExitFailure
+		}
+
+		return constants.ExitSuccess
+	}
+
+	// Else, one line per listing
+	for _, listing := range changes.Added {
+		fmt.Printf("+ %s %s listed for %v SOL by %s\n", listing.Collection, listing.Mint, listing.Price, listing.Seller)
+	}
+	for _, listing := range changes.Removed {
+		fmt.Printf("- %s %s (%v SOL) is no longer listed\n", listing.Collection, listing.Mint, listing.Price)
+	}
+	for _, change := range changes.Repriced {
+		fmt.Printf("~ %s %s repriced from %v to %v SOL\n", change.Collection, change.Mint, change.OldPrice, change.Price)
+	}
+
+	fmt.Printf("%d added, %d removed, %d repriced\n", len(changes.Added), len(changes.Removed), len(changes.Repriced))
+
+	return constants.ExitSuccess
+}
+
+// Reads listings exported to a JSON, NDJSON or CSV file, picking the format by file extension
+func readListingsFile(path string) ([]models.Listing, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	format, _ := writer.ForPath(path)
+
+	switch format.Name {
+	case "json":
+		return formatter.UnmarshalExportJSON(data)
+	case "ndjson":
+		return formatter.UnmarshalExportNDJSON(data)
+	}
+
+	return formatter.UnmarshalExportCSV(data) // Else, CSV
+}
@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"mantas9/listings/models"
+)
+
+// Listing whose price changed between two snapshots
+type PriceChange struct {
+	models.Listing         // Listing in the new snapshot
+	OldPrice       float64 `json:"oldPrice"` // Price in the old snapshot
+}
+
+// Differences between two snapshots of listings
+type Changes struct {
+	Added    []models.Listing `json:"added"`    // Listings only in the new snapshot
+	Removed  []models.Listing `json:"removed"`  // Listings only in the old snapshot
+	Repriced []PriceChange    `json:"repriced"` // Listings in both snapshots with a different price
+}
+
+// Compares an old (before) and a new (after) snapshot of listings by mint address.
+// Added and repriced listings keep the new snapshot's order, removed listings keep the old snapshot's order
+func Compare(before, after []models.Listing) Changes {
+	res := Changes{Added: []models.Listing{}, Removed: []models.Listing{}, Repriced: []PriceChange{}}
+
+	// Index old listings by mint
+	oldByMint := map[string]models.Listing{}
+	for _, listing := range before {
+		oldByMint[listing.Mint] = listing
+	}
+
+	// Added and repriced listings
+	newMints := map[string]bool{}
+	for _, listing := range after {
+		newMints[listing.Mint] = true
+
+		prev, ok := oldByMint[listing.Mint]
+		if !ok {
+			res.Added = append(res.Added, listing)
+		} else if prev.Price != listing.Price {
+			res.Repriced = append(res.Repriced, PriceChange{Listing: listing, OldPrice: prev.Price})
+		}
+	}
+
+	// Removed listings
+	for _, listing := range before {
+		if !newMints[listing.Mint] {
+			res.Removed = append(res.Removed, listing)
+		}
+	}
+
+	return res
+}
+
+// Reports whether there are no differences
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Repriced) == 0
+}
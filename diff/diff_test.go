@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"mantas9/listings/models"
+	"reflect"
+	"testing"
+)
+
+// TestCompare compares snapshots with added, removed and repriced listings
+func TestCompare(t *testing.T) {
+	a := models.Listing{Collection: "degods", Mint: "a", Price: 1}
+	b := models.Listing{Collection: "degods", Mint: "b", Price: 2}
+	c := models.Listing{Collection: "degods", Mint: "c", Price: 3}
+	bCheaper := models.Listing{Collection: "degods", Mint: "b", Price: 1.5}
+
+	// Test table
+	var tests = []struct {
+		name      string
+		old       []models.Listing
+		new       []models.Listing
+		want      Changes
+		wantEmpty bool
+	}{
+		{
+			name: "Added, removed and repriced",
+			old:  []models.Listing{a, b},
+			new:  []models.Listing{bCheaper, c},
+			want: Changes{
+				Added:    []models.Listing{c},
+				Removed:  []models.Listing{a},
+				Repriced: []PriceChange{{Listing: bCheaper, OldPrice: 2}},
+			},
+		},
+		{
+			name:      "Unchanged",
+			old:       []models.Listing{a, b},
+			new:       []models.Listing{b, a},
+			want:      Changes{Added: []models.Listing{}, Removed: []models.Listing{}, Repriced: []PriceChange{}},
+			wantEmpty: true,
+		},
+		{
+			name: "From empty",
+			old:  nil,
+			new:  []models.Listing{a},
+			want: Changes{Added: []models.Listing{a}, Removed: []models.Listing{}, Repriced: []PriceChange{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.old, tt.new)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", got, tt.want)
+			}
+			if got.Empty() != tt.wantEmpty {
+				t.Errorf("Got Empty() %v, wanted %v", got.Empty(), tt.wantEmpty)
+			}
+		})
+	}
+}
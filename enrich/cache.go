@@ -0,0 +1,75 @@
+package enrich
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// On-disk cache of raw token metadata, one JSON file per mint. The zero value caches nothing
+type Cache struct {
+	Dir string // Directory of cached files, "" to disable the cache
+}
+
+// Returns the default cache directory, e.g. ~/.cache/listings/tokens, or "" if there is no user cache directory
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "listings", "tokens")
+}
+
+// Returns the cached metadata of a mint
+func (c Cache) Get(mint string) ([]byte, bool) {
+	path, ok := c.path(mint)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil { // Missing or unreadable
+		return nil, false
+	}
+
+	return data, true
+}
+
+// Stores the metadata of a mint, replacing the cached file atomically
+func (c Cache) Put(mint string, data []byte) error {
+	path, ok := c.path(mint)
+	if !ok {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so readers never see partial files
+	file, err := os.CreateTemp(c.Dir, ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // No-op once renamed
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Returns the path of a mint's cached file, false if caching is disabled or the mint is not a safe file name
+func (c Cache) path(mint string) (string, bool) {
+	if c.Dir == "" || mint == "" || mint == "." || mint == ".." || strings.ContainsAny(mint, `/\`) {
+		return "", false
+	}
+
+	return filepath.Join(c.Dir, mint+".json"), true
+}
@@ -0,0 +1,77 @@
+package enrich
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCache stores and reads token metadata, checking mints which are not safe file names
+func TestCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tokens") // Created on the first Put
+
+	// Test table
+	var tests = []struct {
+		name   string
+		cache  Cache
+		mint   string
+		wantOK bool // Whether the metadata is read back
+	}{
+		{
+			name:   "valid mint",
+			cache:  Cache{Dir: dir},
+			mint:   "BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV",
+			wantOK: true,
+		},
+		{
+			name:  "disabled cache",
+			cache: Cache{},
+			mint:  "BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV",
+		},
+		{
+			name:  "empty mint",
+			cache: Cache{Dir: dir},
+		},
+		{
+			name:  "path traversal",
+			cache: Cache{Dir: dir},
+			mint:  "../escaped",
+		},
+		{
+			name:  "parent directory",
+			cache: Cache{Dir: dir},
+			mint:  "..",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Unsafe mints are skipped without an error
+			if err := tt.cache.Put(tt.mint, []byte(`{"name":"test"}`)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			data, ok := tt.cache.Get(tt.mint)
+			if ok != tt.wantOK {
+				t.Fatalf("Got ok %v, wanted %v", ok, tt.wantOK)
+			}
+			if ok && string(data) != `{"name":"test"}` {
+				t.Errorf("Got %s, wanted the stored metadata", data)
+			}
+		})
+	}
+
+	// Nothing is written outside the cache directory
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escaped.json")); err == nil {
+		t.Errorf("Cache wrote outside of its directory")
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Got %d files in the cache, wanted 1", len(entries))
+	}
+}
@@ -0,0 +1,117 @@
+package enrich
+
+import (
+	"context"
+	"log/slog"
+	"mantas9/listings/formatter"
+	"mantas9/listings/models"
+	"mantas9/listings/orchestrator"
+	"slices"
+)
+
+// Default amount of tokens fetched at the same time
+const DefaultConcurrency = 8
+
+// Fetches the raw metadata of a token
+type TokenFunc func(ctx context.Context, mint string) ([]byte, error)
+
+// Listings call parameters
+type Options struct {
+	Concurrency int          // Maximum amount of tokens fetched at the same time (default - DefaultConcurrency)
+	Cache       Cache        // On-disk cache of token metadata (zero value - no cache)
+	Logger      *slog.Logger // Logger for cache hits and errors (default - discards logs)
+}
+
+// Token which could not be merged into its listings
+type Failure struct {
+	Mint string // Token mint address
+	Err  error  // Fetch or parse error
+}
+
+// Merges token metadata into listings, fetching each distinct mint once, from the cache if possible.
+// Listings whose token could not be fetched are returned unchanged and their mints are reported as failures
+func Listings(ctx context.Context, listings []models.Listing, fetch TokenFunc, opts Options) ([]models.Listing, []Failure) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	// Distinct mints in order of appearance
+	mints := []string{}
+	seen := map[string]bool{}
+	for _, listing := range listings {
+		if listing.Mint != "" && !seen[listing.Mint] {
+			seen[listing.Mint] = true
+			mints = append(mints, listing.Mint)
+		}
+	}
+
+	// Fetch the metadata of every token
+	results := orchestrator.RunSymbols(ctx, mints, func(ctx context.Context, mint string) (models.TokenMetadata, error) {
+		return getToken(ctx, mint, fetch, opts.Cache, logger)
+	}, orchestrator.RunOpts{Concurrency: concurrency})
+
+	tokens := map[string]models.TokenMetadata{} // Fetched metadata by mint
+	failed := []Failure{}
+	for _, res := range results {
+		if res.Err != nil {
+			failed = append(failed, Failure{Mint: res.Symbol, Err: res.Err})
+			continue
+		}
+		tokens[res.Symbol] = res.Value
+	}
+
+	// Merge metadata into copies of the listings
+	res := slices.Clone(listings)
+	for i := range res {
+		token, ok := tokens[res[i].Mint]
+		if !ok {
+			continue
+		}
+
+		res[i].Name = token.Name
+		res[i].Image = token.Image
+		res[i].Attributes = token.Attributes
+	}
+
+	return res, failed
+}
+
+// Returns the metadata of a token from the cache, fetching and caching it on a miss
+func getToken(ctx context.Context, mint string, fetch TokenFunc, cache Cache, logger *slog.Logger) (models.TokenMetadata, error) {
+	// Cached metadata
+	if data, ok := cache.Get(mint); ok {
+		if token, err := formatter.UnmarshalTokenJSON(data); err == nil {
+			logger.Debug("token metadata read from cache", "mint", mint)
+			return token, nil
+		}
+
+		logger.Warn("ignoring invalid cached token metadata", "mint", mint)
+	}
+
+	// Fetch HTTP data
+	data, err := fetch(ctx, mint)
+
+	if err != nil { // Error check
+		return models.TokenMetadata{}, err
+	}
+
+	// Unmarshal JSON data
+	token, err := formatter.UnmarshalTokenJSON(data)
+
+	if err != nil { // Error check
+		return models.TokenMetadata{}, err
+	}
+
+	// The cache is best effort, a failed write only costs a fetch next time
+	if err := cache.Put(mint, data); err != nil {
+		logger.Warn("cannot cache token metadata", "mint", mint, "error", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,140 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"mantas9/listings/models"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestListings merges mock token metadata into listings, checking cache use and failed tokens
+func TestListings(t *testing.T) {
+	listings := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 1, Mint: "mint1"},
+		{Collection: "degods", Seller: "seller2", Price: 2, Mint: "mint2"},
+		{Collection: "degods", Seller: "seller3", Price: 3, Mint: "mint1"}, // Relisted token, fetched once
+	}
+	tokens := map[string]string{
+		"mint1": `{"mintAddress":"mint1","name":"DeGod #1","image":"https://example.com/1.png","attributes":[{"trait_type":"Background","value":"Red"}]}`,
+		"mint2": `{"mintAddress":"mint2","name":"DeGod #2"}`,
+	}
+	enriched := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 1, Mint: "mint1", Name: "DeGod #1", Image: "https://example.com/1.png", Attributes: []models.Attribute{{TraitType: "Background", Value: "Red"}}},
+		{Collection: "degods", Seller: "seller2", Price: 2, Mint: "mint2", Name: "DeGod #2"},
+		{Collection: "degods", Seller: "seller3", Price: 3, Mint: "mint1", Name: "DeGod #1", Image: "https://example.com/1.png", Attributes: []models.Attribute{{TraitType: "Background", Value: "Red"}}},
+	}
+
+	// Test table
+	var tests = []struct {
+		name        string
+		cached      map[string]string // Files in the cache before the run
+		noCache     bool              // Run without a cache
+		failing     string            // Mint whose fetch fails
+		want        []models.Listing
+		wantFetched []string // Fetched mints, in any order
+		wantFailed  []string // Failed mints
+	}{
+		{
+			name:        "empty cache",
+			want:        enriched,
+			wantFetched: []string{"mint1", "mint2"},
+		},
+		{
+			name:        "cache hit",
+			cached:      map[string]string{"mint1": tokens["mint1"]},
+			want:        enriched,
+			wantFetched: []string{"mint2"},
+		},
+		{ // Unparsable cached files are fetched again
+			name:        "invalid cached file",
+			cached:      map[string]string{"mint1": `{"mintAddress":`},
+			want:        enriched,
+			wantFetched: []string{"mint1", "mint2"},
+		},
+		{
+			name:        "no cache",
+			noCache:     true,
+			want:        enriched,
+			wantFetched: []string{"mint1", "mint2"},
+		},
+		{ // Listings of failed tokens are kept without metadata
+			name:        "failed token",
+			failing:     "mint1",
+			want:        []models.Listing{listings[0], enriched[1], listings[2]},
+			wantFetched: []string{"mint1", "mint2"},
+			wantFailed:  []string{"mint1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := Cache{Dir: t.TempDir()}
+			for mint, data := range tt.cached {
+				if err := cache.Put(mint, []byte(data)); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if tt.noCache {
+				cache = Cache{}
+			}
+
+			// Mock token fetches
+			var mu sync.Mutex
+			fetched := map[string]int{}
+			fetch := func(ctx context.Context, mint string) ([]byte, error) {
+				mu.Lock()
+				fetched[mint]++
+				mu.Unlock()
+
+				if mint == tt.failing {
+					return nil, errors.New("mock failure")
+				}
+				return []byte(tokens[mint]), nil
+			}
+
+			got, failed := Listings(context.Background(), listings, fetch, Options{Concurrency: 2, Cache: cache})
+
+			// Validate merged listings
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", got, tt.want)
+			}
+
+			// Validate fetches, each mint at most once
+			if len(fetched) != len(tt.wantFetched) {
+				t.Errorf("Fetched %v, wanted %v", fetched, tt.wantFetched)
+			}
+			for _, mint := range tt.wantFetched {
+				if fetched[mint] != 1 {
+					t.Errorf("Fetched %s %d times, wanted once", mint, fetched[mint])
+				}
+			}
+
+			// Validate failures
+			failedMints := []string{}
+			for _, failure := range failed {
+				failedMints = append(failedMints, failure.Mint)
+			}
+			if !slices.Equal(failedMints, tt.wantFailed) {
+				t.Errorf("Got failed mints %v, wanted %v", failedMints, tt.wantFailed)
+			}
+
+			// Fetched tokens are cached, failed ones are not
+			if tt.noCache {
+				return
+			}
+			for _, mint := range tt.wantFetched {
+				if _, ok := cache.Get(mint); ok == (mint == tt.failing) {
+					t.Errorf("Cached %s: %v", mint, ok)
+				}
+			}
+		})
+	}
+
+	// Listings are copied, not modified
+	if listings[0].Name != "" {
+		t.Errorf("Input listings were modified: %+v", listings[0])
+	}
+}
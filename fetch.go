@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mantas9/listings/constants"
+	"mantas9/listings/enrich"
+	"mantas9/listings/formatter"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"mantas9/listings/orchestrator"
+	"mantas9/listings/writer"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// Fetches listings of every collection and exports them to a file
+func (a *app) runFetch(ctx context.Context) int {
+	// Cancel the run after the global deadline
+	ctx, cancel := withTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	// Writer of the specified format
+	format, ok := writer.Lookup(a.cfg.Format)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", a.cfg.Format)
+		return constants.ExitFailure
+	}
+	w, err := a.newWriter(format)
+	if err != nil { // Invalid template
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return constants.ExitUsage
+	}
+
+	// Streaming formats write each collection as soon as it is fetched, unless listings are sorted across collections
+	if sw, ok := w.(writer.StreamWriter); ok && format.Streaming && a.cfg.Sort == "" {
+		return a.streamFetch(ctx, format, sw)
+	}
+
+	// Fetch every collection
+	allListings, failed := collectResults(a.fetchCollections(ctx, nil))
+	a.reportFailures(ctx, failed)
+
+	// Nothing to write if every collection failed
+	if len(failed) == len(a.cfg.Symbols) && len(allListings) == 0 {
+		return constants.ExitFailure
+	}
+
+	// Sort across collections, before ranks which are not exported are dropped
+	if a.cfg.Sort != "" {
+		sorted, err := formatter.SortListings(allListings, a.cfg.Sort)
+		if err != nil { // Invalid order
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return constants.ExitUsage
+		}
+		allListings = sorted
+	}
+
+	// Keep the selected optional fields only
+	allListings = formatter.SelectFields(allListings, a.cfg.ListingFields)
+
+	// Merge token metadata
+	if a.cfg.Enrich {
+		allListings = a.enrichListings(ctx, allListings)
+	}
+
+	// Export everything in specified format, even when the run was interrupted
+	if err := a.writeOutput(context.WithoutCancel(ctx), format, w, allListings); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in writing listings:\n%s\n", err)
+		return constants.ExitFailure
+	}
+
+	return exitCode(failed, len(a.cfg.Symbols), len(allListings))
+}
+
+// Creates a writer of the format with the export options of the run, parsing the template of template exports
+func (a *app) newWriter(format writer.Format) (writer.Writer, error) {
+	opts := writer.Options{
+		Query:        a.cfg.Listings,
+		Symbols:      a.cfg.Symbols,
+		FetchedAt:    time.Now(),
+		Compression:  a.cfg.Compression,
+		RowGroupSize: a.cfg.RowGroupSize,
+		Color:        a.cfg.ColorOutput(os.Getenv),
+		Top:          a.cfg.Top,
+		Fields:       a.cfg.ListingFields,
+		Sort:         a.cfg.Sort,
+		Raw:          a.cfg.Raw,
+	}
+
+	// Template from --template or --template-string
+	if a.cfg.Template != "" || a.cfg.TemplateString != "" {
+		name, text := "--template-string", a.cfg.TemplateString
+
+		if a.cfg.Template != "" {
+			data, err := os.ReadFile(a.cfg.Template)
+			if err != nil {
+				return nil, fmt.Errorf("reading template: %w", err)
+			}
+			name, text = filepath.Base(a.cfg.Template), string(data)
+		}
+
+		tmpl, err := writer.ParseTemplate(name, text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
+		}
+		opts.Template = tmpl
+	}
+
+	return format.New(opts), nil
+}
+
+// Fetches every collection, writing the listings of each one to the output as soon as it is fetched.
+// The output is opened with the first fetched collection, so a run where every collection fails keeps an existing file
+func (a *app) streamFetch(ctx context.Context, format writer.Format, sw writer.StreamWriter) int {
+	// Write even when the run is interrupted
+	writeCtx := context.WithoutCancel(ctx)
+
+	var stream writer.Stream // Stream to the output, nil until a collection is fetched
+	var closeOut func() error
+	var path string
+
+	// Write each collection in completion order, stopping at the first write error
+	var writeErr error
+	written := 0 // Amount of written listings
+	results := a.fetchCollections(ctx, func(res orchestrator.Result) {
+		if writeErr != nil || (res.Err != nil && len(res.Listings) == 0) { // Nothing to write
+			return
+		}
+
+		// Open output with the first fetched collection
+		if stream == nil {
+			stream, closeOut, path, writeErr = a.openStream(writeCtx, format, sw)
+			if writeErr != nil {
+				return
+			}
+		}
+
+		listings := formatter.SelectFields(res.Listings, a.cfg.ListingFields)
+		if a.cfg.Enrich {
+			listings = a.enrichListings(ctx, listings)
+		}
+		writeErr = stream.WriteBatch(writeCtx, listings)
+		if writeErr == nil {
+			written += len(listings)
+		}
+	})
+
+	// Finish output
+	if stream != nil {
+		if err := stream.Close(); writeErr == nil {
+			writeErr = err
+		}
+		if err := closeOut(); writeErr == nil { // Report failed flushes
+			writeErr = err
+		}
+	}
+
+	_, failed := collectResults(results)
+	a.reportFailures(ctx, failed)
+
+	if writeErr != nil {
+		fmt.Fprintf(os.Stderr, "Error in writing listings:\n%s\n", writeErr)
+		return constants.ExitFailure
+	}
+
+	// Nothing to write if every collection failed
+	if stream == nil {
+		return constants.ExitFailure
+	}
+
+	// Print success message
+	if a.cfg.Output != "-" {
+		fmt.Fprintf(os.Stderr, "Your selected NFT Listings' data has been written to %s successfully.\n", path)
+	}
+
+	return exitCode(failed, len(a.cfg.Symbols), written)
+}
+
+// Opens --output and starts a stream of sw to it, returning the stream, a function closing the output and its path
+func (a *app) openStream(ctx context.Context, format writer.Format, sw writer.StreamWriter) (writer.Stream, func() error, string, error) {
+	out, closeOut, path, err := a.openOutput(format)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	stream, err := sw.Stream(ctx, out)
+	if err != nil {
+		closeOut()
+		return nil, nil, "", err
+	}
+
+	return stream, closeOut, path, nil
+}
+
+// Merges token metadata into listings with --enrich, warning about tokens which could not be fetched
+func (a *app) enrichListings(ctx context.Context, listings []models.Listing) []models.Listing {
+	cache := enrich.Cache{Dir: a.cfg.CacheDir}
+	if cache.Dir == "" {
+		cache.Dir = enrich.DefaultCacheDir()
+	}
+	if a.cfg.NoCache {
+		cache = enrich.Cache{}
+	}
+
+	enriched, failed := enrich.Listings(ctx, listings, a.client.GetToken, enrich.Options{
+		Concurrency: a.cfg.EnrichConcurrency,
+		Cache:       cache,
+		Logger:      a.logger,
+	})
+
+	// Listings of failed tokens are exported without metadata
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "Metadata of %d tokens could not be fetched, their listings are exported without it (%s: %s).\n\n",
+			len(failed), failed[0].Mint, tokenFailureReason(failed[0].Err))
+	}
+
+	return enriched
+}
+
+// Prints failed collections, warning that the export is partial if the run was interrupted
+func (a *app) reportFailures(ctx context.Context, failed []orchestrator.Result) {
+	if len(failed) == 0 {
+		return
+	}
+
+	printFailures(failed, len(a.cfg.Symbols))
+
+	// Warn user that not every collection was fetched
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "The run was interrupted (%v), the export is partial and only contains what was fetched so far.\n\n", context.Cause(ctx))
+	}
+}
+
+// Writes listings with w to --output
+func (a *app) writeOutput(ctx context.Context, format writer.Format, w writer.Writer, listings []models.Listing) error {
+	// Formats managing their own file, e.g. databases
+	if fw, ok := w.(writer.FileWriter); ok && format.FileOnly {
+		path := a.outputPath(format)
+
+		if err := fw.WriteFile(ctx, path, listings); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Your selected NFT Listings' data has been written to %s successfully.\n", path)
+
+		return nil
+	}
+
+	out, closeOut, path, err := a.openOutput(format)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Write(ctx, out, listings); err != nil {
+		closeOut()
+		return err
+	}
+
+	if err := closeOut(); err != nil { // Report failed flushes
+		return err
+	}
+
+	// Print success message
+	if a.cfg.Output != "-" {
+		fmt.Fprintf(os.Stderr, "Your selected NFT Listings' data has been written to %s successfully.\n", path)
+	}
+
+	return nil
+}
+
+// Opens --output (listings.<format> by default, stdout for "-"), returning the writer, a function closing it and its path
+func (a *app) openOutput(format writer.Format) (io.Writer, func() error, string, error) {
+	// Stdout
+	if a.cfg.Output == "-" {
+		return os.Stdout, func() error { return nil }, "-", nil
+	}
+
+	// File
+	path := a.outputPath(format)
+
+	file, err := os.Create(path) // Create file, truncating it if exists
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return file, file.Close, path, nil
+}
+
+// Returns the path of the export file (listings.<format> by default, stats.<format> or activities.<format> for those commands)
+func (a *app) outputPath(format writer.Format) string {
+	if a.cfg.Output != "" {
+		return a.cfg.Output
+	}
+
+	switch a.cfg.Command {
+	case "stats":
+		return format.Filename("stats")
+	case "activity":
+		return format.Filename("activities")
+	}
+
+	return format.DefaultFilename()
+}
+
+// Fetches every collection through a bounded worker pool, each with its own options, returning results in command line order.
+// onResult (optional) is called with each result as soon as it is fetched
+func (a *app) fetchCollections(ctx context.Context, onResult func(orchestrator.Result)) []orchestrator.Result {
+	return orchestrator.Run(ctx, orchestrator.Jobs(a.cfg.Listings, a.cfg.Symbols), func(ctx context.Context, opts httpfetcher.GetListingsOpts) ([]models.Listing, error) {
+		return getListings(ctx, a.client, opts)
+	}, orchestrator.RunOpts{Concurrency: a.cfg.Concurrency, FailFast: a.cfg.FailFast, OnResult: onResult})
+}
+
+// Combines listings of every result and returns them with the failed results, warning about collections without listings
+func collectResults(results []orchestrator.Result) ([]models.Listing, []orchestrator.Result) {
+	var allListings []models.Listing // Combined list of all fetched listings
+	var failed []orchestrator.Result // Collections which failed to fetch
+
+	// Iterate through results in command line order
+	for _, res := range results {
+		allListings = append(allListings, res.Listings...) // Append all listings data to main list, including partially fetched collections
+
+		if res.Err != nil { // Record failure
+			failed = append(failed, res)
+		} else if len(res.Listings) <= 0 { // Warn user about no matches for his collection
+			fmt.Fprintf(os.Stderr, `There are no matching Listings for the collection "%v" on the MagicEden Marketplace according to your parameters.`+"\nThis collection will be skipped.\n\n", res.Symbol) // Warn user
+		}
+	}
+
+	return allListings, failed
+}
+
+// Tells total failure and partial success apart from a fully successful run. A run where every collection
+// failed still succeeded partially if it exported the items fetched before failing, e.g. when interrupted
+func exitCode(failed []orchestrator.Result, total, exported int) int {
+	if len(failed) == total && total > 0 && exported == 0 {
+		return constants.ExitFailure
+	}
+	if len(failed) > 0 {
+		return constants.ExitPartial
+	}
+
+	return constants.ExitSuccess
+}
+
+// Prints a summary table of failed collections to stderr
+func printFailures(failed []orchestrator.Result, total int) {
+	fmt.Fprintf(os.Stderr, "\n%d of %d collections failed:\n", len(failed), total)
+
+	table := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "COLLECTION\tLISTINGS\tERROR")
+
+	for _, res := range failed {
+		fmt.Fprintf(table, "%s\t%d\t%s\n", res.Symbol, len(res.Listings), failureReason(res.Err))
+	}
+
+	table.Flush()
+	fmt.Fprintln(os.Stderr)
+}
+
+// Returns a short, user-facing explanation of a fetch error
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, orchestrator.ErrFailFast):
+		return "cancelled (fail-fast)"
+	case errors.Is(err, httpfetcher.ErrCollectionNotFound):
+		return "collection not found"
+	case errors.Is(err, httpfetcher.ErrRateLimited):
+		return "rate limited by API after every retry (try again later or lower --rps)"
+	case errors.Is(err, httpfetcher.ErrInvalidSymbol):
+		return "invalid collection symbol"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out (--timeout)"
+	}
+
+	return err.Error()
+}
+
+// Returns a short, user-facing explanation of a token fetch error
+func tokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, httpfetcher.ErrTokenNotFound):
+		return "token not found"
+	case errors.Is(err, httpfetcher.ErrInvalidMint):
+		return "invalid mint address"
+	}
+
+	return failureReason(err)
+}
+
+func getListings(ctx context.Context, client *httpfetcher.Client, options httpfetcher.GetListingsOpts) ([]models.Listing, error) {
+	// Fetch HTTP data
+	data, err := client.GetListings(ctx, options)
+
+	// Error check, keeping partial data of interrupted fetches
+	if err != nil && data == nil {
+		return []models.Listing{}, err
+	}
+	fetchErr := err
+
+	// Unmarshal JSON data
+	res, err := formatter.UnmarshalJSON(data)
+
+	// Error check
+	if err != nil {
+		return []models.Listing{}, err
+	}
+
+	return res, fetchErr
+}
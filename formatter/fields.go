@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"mantas9/listings/models"
+	"slices"
+	"strconv"
+)
+
+// Optional field of listings, only exported when selected with --fields
+type ListingField struct {
+	Name  string                      // Name in exports and --fields
+	Value func(models.Listing) string // Text of the field, e.g. a CSV cell
+	clear func(*models.Listing)       // Zeroes the field
+}
+
+// Optional listing fields, in export order
+var ListingFields = []ListingField{
+	{
+		Name:  "pdaAddress",
+		Value: func(l models.Listing) string { return l.PDAAddress },
+		clear: func(l *models.Listing) { l.PDAAddress = "" },
+	},
+	{
+		Name:  "auctionHouse",
+		Value: func(l models.Listing) string { return l.AuctionHouse },
+		clear: func(l *models.Listing) { l.AuctionHouse = "" },
+	},
+	{
+		Name:  "tokenSize",
+		Value: func(l models.Listing) string { return strconv.FormatInt(l.TokenSize, 10) },
+		clear: func(l *models.Listing) { l.TokenSize = 0 },
+	},
+	{
+		Name:  "expiry",
+		Value: func(l models.Listing) string { return strconv.FormatInt(l.Expiry, 10) },
+		clear: func(l *models.Listing) { l.Expiry = 0 },
+	},
+	{
+		Name:  "rarity",
+		Value: func(l models.Listing) string { return string(l.Rarity) },
+		clear: func(l *models.Listing) { l.Rarity = nil },
+	},
+	{
+		Name:  "rank",
+		Value: func(l models.Listing) string { return formatRank(l.Rank) },
+		clear: func(l *models.Listing) { l.Rank = 0 },
+	},
+	{
+		Name:  "meRank",
+		Value: func(l models.Listing) string { return formatRank(l.MERank) },
+		clear: func(l *models.Listing) { l.MERank = 0 },
+	},
+	{
+		Name:  "moonRank",
+		Value: func(l models.Listing) string { return formatRank(l.MoonRank) },
+		clear: func(l *models.Listing) { l.MoonRank = 0 },
+	},
+	{
+		Name:  "howRareRank",
+		Value: func(l models.Listing) string { return formatRank(l.HowRareRank) },
+		clear: func(l *models.Listing) { l.HowRareRank = 0 },
+	},
+	{
+		Name:  "listingSource",
+		Value: func(l models.Listing) string { return l.ListingSource },
+		clear: func(l *models.Listing) { l.ListingSource = "" },
+	},
+	{
+		Name:  "img",
+		Value: func(l models.Listing) string { return l.Img },
+		clear: func(l *models.Listing) { l.Img = "" },
+	},
+	{
+		Name:  "priceLamports",
+		Value: func(l models.Listing) string { return strconv.FormatInt(l.PriceLamports, 10) },
+		clear: func(l *models.Listing) { l.PriceLamports = 0 },
+	},
+}
+
+// Formats a rarity rank, empty if unranked
+func formatRank(rank int64) string {
+	if rank <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(rank, 10)
+}
+
+// Returns the names of optional listing fields, in export order
+func FieldNames() []string {
+	names := []string{}
+	for _, field := range ListingFields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+// Returns the optional listing fields with the given names, in export order
+func SelectedFields(names []string) []ListingField {
+	res := []ListingField{}
+	for _, field := range ListingFields {
+		if slices.Contains(names, field.Name) {
+			res = append(res, field)
+		}
+	}
+
+	return res
+}
+
+// Returns copies of listings without the optional fields which are not selected, so JSON exports omit them
+func SelectFields(listings []models.Listing, names []string) []models.Listing {
+	res := slices.Clone(listings)
+
+	for _, field := range ListingFields {
+		if slices.Contains(names, field.Name) {
+			continue
+		}
+
+		for i := range res {
+			field.clear(&res[i])
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,68 @@
+package formatter
+
+import (
+	"encoding/json"
+	"mantas9/listings/models"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestSelectFields keeps the selected optional fields of listings and checks the values of every field
+func TestSelectFields(t *testing.T) {
+	listing := models.Listing{Collection: "degods", Seller: "seller1", Price: 5.3885, Mint: "mint1",
+		PDAAddress: "pda1", AuctionHouse: "house1", TokenSize: 1, Expiry: -1, Rarity: json.RawMessage(`{"moonrank":{"rank":12}}`), Rank: 12, MoonRank: 12,
+		ListingSource: "M2", Img: "https://example.com/1.png", PriceLamports: 5388500000, Raw: json.RawMessage(`{"seller":"seller1"}`)}
+	base := models.Listing{Collection: "degods", Seller: "seller1", Price: 5.3885, Mint: "mint1", Raw: json.RawMessage(`{"seller":"seller1"}`)}
+
+	// Test table
+	var tests = []struct {
+		name       string
+		fields     []string
+		want       models.Listing
+		wantValues []string // Values of the selected fields
+	}{
+		{ // Original API objects are kept for --raw
+			name: "No fields",
+			want: base,
+		},
+		{
+			name:   "Some fields",
+			fields: []string{"rarity", "priceLamports"},
+			want: models.Listing{Collection: "degods", Seller: "seller1", Price: 5.3885, Mint: "mint1",
+				Rarity: json.RawMessage(`{"moonrank":{"rank":12}}`), PriceLamports: 5388500000, Raw: json.RawMessage(`{"seller":"seller1"}`)},
+			wantValues: []string{`{"moonrank":{"rank":12}}`, "5388500000"},
+		},
+		{
+			name:       "All fields",
+			fields:     FieldNames(),
+			want:       listing,
+			wantValues: []string{"pda1", "house1", "1", "-1", `{"moonrank":{"rank":12}}`, "12", "", "12", "", "M2", "https://example.com/1.png", "5388500000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectFields([]models.Listing{listing}, tt.fields)
+
+			// Compare selected listing
+			if !reflect.DeepEqual(got, []models.Listing{tt.want}) {
+				t.Errorf("Got %+v, wanted %+v", got, tt.want)
+			}
+
+			// Compare values of the selected fields
+			values := []string{}
+			for _, field := range SelectedFields(tt.fields) {
+				values = append(values, field.Value(listing))
+			}
+			if !slices.Equal(values, tt.wantValues) {
+				t.Errorf("Got values %v, wanted %v", values, tt.wantValues)
+			}
+		})
+	}
+
+	// The input is copied, not modified
+	if listing.PDAAddress != "pda1" {
+		t.Errorf("Input listing was modified: %+v", listing)
+	}
+}
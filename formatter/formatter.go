@@ -1,16 +1,23 @@
 package formatter
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"mantas9/listings/models"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gocarina/gocsv"
 )
 
-// Unmarshals JSON data to struct
+// Unmarshals JSON data to struct, keeping the original object of each listing
 func UnmarshalJSON(input []byte) ([]models.Listing, error) {
-	jsonStruct := []models.ListingJSON{} // Json struct for seamless unmarshalling
+	rawStruct := []json.RawMessage{} // Original listing objects
 
-	// Unmarshal into jsonStruct
-	err := json.Unmarshal(input, &jsonStruct)
+	// Unmarshal into rawStruct
+	err := json.Unmarshal(input, &rawStruct)
 
 	if err != nil { // Error check
 		return []models.Listing{}, err
@@ -20,9 +27,184 @@ func UnmarshalJSON(input []byte) ([]models.Listing, error) {
 	res := []models.Listing{} // Result
 
 	// Iterate through each listing
-	for i := range jsonStruct {
+	for _, raw := range rawStruct {
+		jsonStruct := models.ListingJSON{} // Json struct for seamless unmarshalling
+
+		if err := json.Unmarshal(raw, &jsonStruct); err != nil { // Error check
+			return []models.Listing{}, err
+		}
+
+		rarity := parseRarity(jsonStruct.Rarity)
+
 		// Append converted jsonStruct value to result
-		res = append(res, models.Listing{Collection: jsonStruct[i].TokenData.Collection, Seller: jsonStruct[i].Seller, Price: jsonStruct[i].Price, Mint: jsonStruct[i].TokenData.Mint})
+		res = append(res, models.Listing{
+			Collection:    jsonStruct.TokenData.Collection,
+			Seller:        jsonStruct.Seller,
+			Price:         jsonStruct.Price,
+			Mint:          jsonStruct.TokenData.Mint,
+			PDAAddress:    jsonStruct.PDAAddress,
+			AuctionHouse:  jsonStruct.AuctionHouse,
+			TokenSize:     jsonStruct.TokenSize,
+			Expiry:        jsonStruct.Expiry,
+			Rarity:        compactJSON(jsonStruct.Rarity),
+			ListingSource: jsonStruct.ListingSource,
+			Img:           jsonStruct.Extra.Img,
+			PriceLamports: priceLamports(jsonStruct),
+			Rank:          rarity.Rank(),
+			MERank:        rarity.MERank(),
+			MoonRank:      rarity.Moonrank.Rank,
+			HowRareRank:   rarity.HowRare.Rank,
+			Raw:           raw,
+		})
+	}
+
+	return res, nil
+}
+
+// Parses the ranks of a listing's rarity, leaving malformed ranks unset instead of failing the listing
+func parseRarity(raw json.RawMessage) models.RarityJSON {
+	rarity := models.RarityJSON{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &rarity) // Partially filled on errors
+	}
+
+	return rarity
+}
+
+// Returns the lamports of a listing's price info, derived from its SOL price if missing
+func priceLamports(listing models.ListingJSON) int64 {
+	if lamports, err := listing.PriceInfo.SolPrice.RawAmount.Int64(); err == nil {
+		return lamports
+	}
+
+	return int64(math.Round(listing.Price * models.LamportsPerSOL))
+}
+
+// Removes insignificant whitespace from a JSON value, dropping null and empty values
+func compactJSON(raw json.RawMessage) json.RawMessage {
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil || b.String() == "null" || b.Len() == 0 {
+		return nil
+	}
+
+	return b.Bytes()
+}
+
+// Unmarshals collection stats JSON data to struct, converting prices from lamports to SOL
+func UnmarshalStatsJSON(input []byte) (models.CollectionStats, error) {
+	jsonStruct := models.CollectionStatsJSON{} // Json struct for seamless unmarshalling
+
+	// Unmarshal into jsonStruct
+	if err := json.Unmarshal(input, &jsonStruct); err != nil { // Error check
+		return models.CollectionStats{}, err
+	}
+
+	return models.CollectionStats{
+		Collection:  jsonStruct.Symbol,
+		FloorPrice:  jsonStruct.FloorPrice / models.LamportsPerSOL,
+		ListedCount: jsonStruct.ListedCount,
+		AvgPrice24h: jsonStruct.AvgPrice24hr / models.LamportsPerSOL,
+		TotalVolume: jsonStruct.VolumeAll / models.LamportsPerSOL,
+	}, nil
+}
+
+// Unmarshals collection activities JSON data to struct. Activities without a collection get the given symbol
+func UnmarshalActivitiesJSON(input []byte, symbol string) ([]models.Activity, error) {
+	jsonStruct := []models.ActivityJSON{} // Json struct for seamless unmarshalling
+
+	// Unmarshal into jsonStruct
+	if err := json.Unmarshal(input, &jsonStruct); err != nil { // Error check
+		return []models.Activity{}, err
+	}
+
+	// Convert to CSV-compatible struct
+	res := []models.Activity{} // Result
+
+	for _, activity := range jsonStruct {
+		collection := activity.Collection
+		if collection == "" {
+			collection = symbol
+		}
+
+		res = append(res, models.Activity{
+			Collection: collection,
+			Type:       activity.Type,
+			Time:       time.Unix(activity.BlockTime, 0).UTC(),
+			Price:      activity.Price,
+			Seller:     activity.Seller,
+			Buyer:      activity.Buyer,
+			Mint:       activity.TokenMint,
+			Signature:  activity.Signature,
+			Source:     activity.Source,
+		})
+	}
+
+	return res, nil
+}
+
+// Unmarshals token metadata JSON data to struct, converting trait values to strings
+func UnmarshalTokenJSON(input []byte) (models.TokenMetadata, error) {
+	jsonStruct := models.TokenMetadataJSON{} // Json struct for seamless unmarshalling
+
+	// Unmarshal into jsonStruct
+	if err := json.Unmarshal(input, &jsonStruct); err != nil { // Error check
+		return models.TokenMetadata{}, err
+	}
+
+	res := models.TokenMetadata{Mint: jsonStruct.Mint, Name: jsonStruct.Name, Image: jsonStruct.Image}
+	for _, attribute := range jsonStruct.Attributes {
+		value := ""
+		switch v := attribute.Value.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+		default:
+			value = fmt.Sprint(v)
+		}
+
+		res.Attributes = append(res.Attributes, models.Attribute{TraitType: attribute.TraitType, Value: value})
+	}
+
+	return res, nil
+}
+
+// Unmarshals listings previously exported by writer.WriteJSON
+func UnmarshalExportJSON(input []byte) ([]models.Listing, error) {
+	res := []models.Listing{} // Result
+
+	if err := json.Unmarshal(input, &res); err != nil { // Error check
+		return []models.Listing{}, err
+	}
+
+	return res, nil
+}
+
+// Unmarshals listings previously exported in the ndjson format (one JSON object per line)
+func UnmarshalExportNDJSON(input []byte) ([]models.Listing, error) {
+	res := []models.Listing{} // Result
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	for dec.More() {
+		var listing models.Listing
+
+		if err := dec.Decode(&listing); err != nil { // Error check
+			return []models.Listing{}, err
+		}
+
+		res = append(res, listing)
+	}
+
+	return res, nil
+}
+
+// Unmarshals listings previously exported by writer.WriteCSV
+func UnmarshalExportCSV(input []byte) ([]models.Listing, error) {
+	res := []models.Listing{} // Result
+
+	if err := gocsv.UnmarshalBytes(input, &res); err != nil { // Error check
+		return []models.Listing{}, err
 	}
 
 	return res, nil
@@ -1,13 +1,18 @@
 package formatter
 
 import (
+	"encoding/json"
 	"mantas9/listings/models"
 	"reflect"
 	"testing"
+	"time"
 )
 
 // TestUnmarshalJSON calls formatter.UnmarshalJSON with a Valid, Empty and invalid JSON input, checking for valid return values
 func TestUnmarshalJSON(t *testing.T) {
+	// Listing object of a real API response
+	valid := `{"pdaAddress":"HdddQqvN3Rri7ViPdXGdMTNAF2P5g7JQLvzVRE6yenbP","auctionHouse":"E8cU1WiRWjanGxmn96ewBgk9vPTcL6AEZ1t6F6fkgUWe","tokenAddress":"588ETYtsMZevJJAJ5YxyLUu9ZvytPB11SondgQczxPxn","tokenMint":"4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt","seller":"hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B","sellerReferral":"autMW8SgBkVYeBgqYiTuJZnkvDZMVU2MHJh9Jh7CSQ2","tokenSize":1,"price":5.3885,"priceInfo":{"solPrice":{"rawAmount":"5388500000","address":"So11111111111111111111111111111111111111112","decimals":9}},"rarity":{"meInstant":{"rank":3936}},"extra":{"img":"https://metadata.degods.com/g/3202-dead-rm.png"},"expiry":-1,"token":{"mintAddress":"4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt","owner":"hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B","supply":1,"collection":"degods","collectionName":"DeGods","name":"DeGod #3203","updateAuthority":"AxFuniPo7RaDgPH6Gizf4GZmLQFc4M5ipckeeZfkrPNn","primarySaleHappened":true,"sellerFeeBasisPoints":333,"image":"https://metadata.degods.com/g/3202-dead-rm.png","animationUrl":"https://animation-url.degods.com?tokenId=3202","attributes":[{"trait_type":"background","value":"Red"},{"trait_type":"skin","value":"Turquoise"},{"trait_type":"specialty","value":"God of War"},{"trait_type":"clothes","value":"Caesar Tunic"},{"trait_type":"neck","value":"None"},{"trait_type":"head","value":"Leaf Laurel"},{"trait_type":"eyes","value":"None"},{"trait_type":"mouth","value":"Hipster Beard"},{"trait_type":"version","value":"S3 - Male"},{"trait_type":"y00t","value":"Claimed"}],"properties":{"files":[{"uri":"https://metadata.degods.com/g/3202-dead-rm.png","type":"image/png"}],"category":"image","creators":[{"address":"AxFuniPo7RaDgPH6Gizf4GZmLQFc4M5ipckeeZfkrPNn","share":100}]},"price":5.3885,"listStatus":"listed","tokenAddress":"588ETYtsMZevJJAJ5YxyLUu9ZvytPB11SondgQczxPxn","priceInfo":{"solPrice":{"rawAmount":"5388500000","address":"So11111111111111111111111111111111111111112","decimals":9}}},"listingSource":"M2"}`
+
 	// Create test table
 	var tests = []struct {
 		name      string
@@ -16,9 +21,32 @@ func TestUnmarshalJSON(t *testing.T) {
 		expectErr bool // Should script be expecting an error?
 	}{
 		{ // Valid input expects valid output
-			name:      "Valid",
-			input:     []byte(`[{"pdaAddress":"HdddQqvN3Rri7ViPdXGdMTNAF2P5g7JQLvzVRE6yenbP","auctionHouse":"E8cU1WiRWjanGxmn96ewBgk9vPTcL6AEZ1t6F6fkgUWe","tokenAddress":"588ETYtsMZevJJAJ5YxyLUu9ZvytPB11SondgQczxPxn","tokenMint":"4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt","seller":"hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B","sellerReferral":"autMW8SgBkVYeBgqYiTuJZnkvDZMVU2MHJh9Jh7CSQ2","tokenSize":1,"price":5.3885,"priceInfo":{"solPrice":{"rawAmount":"5388500000","address":"So11111111111111111111111111111111111111112","decimals":9}},"rarity":{"meInstant":{"rank":3936}},"extra":{"img":"https://metadata.degods.com/g/3202-dead-rm.png"},"expiry":-1,"token":{"mintAddress":"4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt","owner":"hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B","supply":1,"collection":"degods","collectionName":"DeGods","name":"DeGod #3203","updateAuthority":"AxFuniPo7RaDgPH6Gizf4GZmLQFc4M5ipckeeZfkrPNn","primarySaleHappened":true,"sellerFeeBasisPoints":333,"image":"https://metadata.degods.com/g/3202-dead-rm.png","animationUrl":"https://animation-url.degods.com?tokenId=3202","attributes":[{"trait_type":"background","value":"Red"},{"trait_type":"skin","value":"Turquoise"},{"trait_type":"specialty","value":"God of War"},{"trait_type":"clothes","value":"Caesar Tunic"},{"trait_type":"neck","value":"None"},{"trait_type":"head","value":"Leaf Laurel"},{"trait_type":"eyes","value":"None"},{"trait_type":"mouth","value":"Hipster Beard"},{"trait_type":"version","value":"S3 - Male"},{"trait_type":"y00t","value":"Claimed"}],"properties":{"files":[{"uri":"https://metadata.degods.com/g/3202-dead-rm.png","type":"image/png"}],"category":"image","creators":[{"address":"AxFuniPo7RaDgPH6Gizf4GZmLQFc4M5ipckeeZfkrPNn","share":100}]},"price":5.3885,"listStatus":"listed","tokenAddress":"588ETYtsMZevJJAJ5YxyLUu9ZvytPB11SondgQczxPxn","priceInfo":{"solPrice":{"rawAmount":"5388500000","address":"So11111111111111111111111111111111111111112","decimals":9}}},"listingSource":"M2"}]`),
-			want:      []models.Listing{{Collection: "degods", Seller: "hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B", Price: 5.3885, Mint: "4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt"}},
+			name:  "Valid",
+			input: []byte("[" + valid + "]"),
+			want: []models.Listing{{Collection: "degods", Seller: "hero2pbJAsW6iGxkiGBwbptJXeoR4eJuwJQFnp1qa3B", Price: 5.3885, Mint: "4yeWHnJ11vYYVJ9QWZiCxsiuXA2tQkj15AaczN6Z4hUt",
+				PDAAddress: "HdddQqvN3Rri7ViPdXGdMTNAF2P5g7JQLvzVRE6yenbP", AuctionHouse: "E8cU1WiRWjanGxmn96ewBgk9vPTcL6AEZ1t6F6fkgUWe", TokenSize: 1, Expiry: -1,
+				Rarity: json.RawMessage(`{"meInstant":{"rank":3936}}`), Rank: 3936, MERank: 3936, ListingSource: "M2", Img: "https://metadata.degods.com/g/3202-dead-rm.png", PriceLamports: 5388500000,
+				Raw: json.RawMessage(valid)}},
+			expectErr: false,
+		},
+		{ // Listings without optional fields get their lamports from the SOL price
+			name:  "Minimal",
+			input: []byte(`[{"seller":"seller1","price":1.5,"rarity":null,"token":{"mintAddress":"mint1","collection":"degods"}}]`),
+			want: []models.Listing{{Collection: "degods", Seller: "seller1", Price: 1.5, Mint: "mint1", PriceLamports: 1500000000,
+				Raw: json.RawMessage(`{"seller":"seller1","price":1.5,"rarity":null,"token":{"mintAddress":"mint1","collection":"degods"}}`)}},
+			expectErr: false,
+		},
+		{ // Magic Eden ranks come first, malformed ranks are left unset
+			name:  "Rarity providers",
+			input: []byte(`[{"seller":"seller1","price":2,"priceInfo":{"solPrice":{"rawAmount":"2000000000"}},"rarity":{"howrare":{"rank":"n/a"},"moonrank":{"rank":40},"merarity":{"rank":35}},"token":{"mintAddress":"mint1","collection":"degods"}},{"seller":"seller2","price":3,"rarity":{"howrare":{"rank":"n/a"},"moonrank":{"rank":900}},"token":{"mintAddress":"mint2","collection":"degods"}}]`),
+			want: []models.Listing{
+				{Collection: "degods", Seller: "seller1", Price: 2, Mint: "mint1", PriceLamports: 2000000000,
+					Rarity: json.RawMessage(`{"howrare":{"rank":"n/a"},"moonrank":{"rank":40},"merarity":{"rank":35}}`), Rank: 35, MERank: 35, MoonRank: 40,
+					Raw: json.RawMessage(`{"seller":"seller1","price":2,"priceInfo":{"solPrice":{"rawAmount":"2000000000"}},"rarity":{"howrare":{"rank":"n/a"},"moonrank":{"rank":40},"merarity":{"rank":35}},"token":{"mintAddress":"mint1","collection":"degods"}}`)},
+				{Collection: "degods", Seller: "seller2", Price: 3, Mint: "mint2", PriceLamports: 3000000000,
+					Rarity: json.RawMessage(`{"howrare":{"rank":"n/a"},"moonrank":{"rank":900}}`), Rank: 900, MoonRank: 900,
+					Raw: json.RawMessage(`{"seller":"seller2","price":3,"rarity":{"howrare":{"rank":"n/a"},"moonrank":{"rank":900}},"token":{"mintAddress":"mint2","collection":"degods"}}`)},
+			},
 			expectErr: false,
 		},
 		{ // Empty input expects an empty array
@@ -59,3 +87,240 @@ func TestUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+// TestUnmarshalExport calls UnmarshalExportJSON and UnmarshalExportCSV with exported data and validates the parsed listings
+func TestUnmarshalExport(t *testing.T) {
+	want := []models.Listing{
+		{Collection: "degods", Seller: "9taD9QshRxnMzsPcnYpcamu66pwQurfyQ29tbkZVdrS6", Price: 5.2084, Mint: "DNLRw1cWR8nbfgBuxsdiP6KAHMoEFvAVCRgc4PjQbEoY"},
+		{Collection: "degods", Seller: "skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA", Price: 5.2094, Mint: "BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"},
+	}
+
+	// Create test table
+	var tests = []struct {
+		name      string
+		unmarshal func([]byte) ([]models.Listing, error)
+		input     []byte
+		want      []models.Listing
+		expectErr bool
+	}{
+		{
+			name:      "JSON",
+			unmarshal: UnmarshalExportJSON,
+			input:     []byte(`[{"collection":"degods","seller":"9taD9QshRxnMzsPcnYpcamu66pwQurfyQ29tbkZVdrS6","price":5.2084,"mintAddress":"DNLRw1cWR8nbfgBuxsdiP6KAHMoEFvAVCRgc4PjQbEoY"},{"collection":"degods","seller":"skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA","price":5.2094,"mintAddress":"BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"}]`),
+			want:      want,
+			expectErr: false,
+		},
+		{
+			name:      "CSV",
+			unmarshal: UnmarshalExportCSV,
+			input:     []byte("collection,seller,price,mintAddress\ndegods,9taD9QshRxnMzsPcnYpcamu66pwQurfyQ29tbkZVdrS6,5.2084,DNLRw1cWR8nbfgBuxsdiP6KAHMoEFvAVCRgc4PjQbEoY\ndegods,skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA,5.2094,BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV\n"),
+			want:      want,
+			expectErr: false,
+		},
+		{ // Metadata columns of --enrich exports are ignored
+			name:      "Enriched CSV",
+			unmarshal: UnmarshalExportCSV,
+			input:     []byte("collection,seller,price,mintAddress,name,image,attributes.Background\ndegods,9taD9QshRxnMzsPcnYpcamu66pwQurfyQ29tbkZVdrS6,5.2084,DNLRw1cWR8nbfgBuxsdiP6KAHMoEFvAVCRgc4PjQbEoY,DeGod #1,,Red\ndegods,skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA,5.2094,BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV,DeGod #2,,\n"),
+			want:      want,
+			expectErr: false,
+		},
+		{
+			name:      "NDJSON",
+			unmarshal: UnmarshalExportNDJSON,
+			input:     []byte(`{"collection":"degods","seller":"9taD9QshRxnMzsPcnYpcamu66pwQurfyQ29tbkZVdrS6","price":5.2084,"mintAddress":"DNLRw1cWR8nbfgBuxsdiP6KAHMoEFvAVCRgc4PjQbEoY"}` + "\n" + `{"collection":"degods","seller":"skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA","price":5.2094,"mintAddress":"BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"}` + "\n"),
+			want:      want,
+			expectErr: false,
+		},
+		{
+			name:      "Invalid NDJSON",
+			unmarshal: UnmarshalExportNDJSON,
+			input:     []byte(`{"collection":"degods"}` + "\n" + `{"price":`),
+			want:      []models.Listing{},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			unmarshal: UnmarshalExportJSON,
+			input:     []byte(`[{"collection":`),
+			want:      []models.Listing{},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid CSV",
+			unmarshal: UnmarshalExportCSV,
+			input:     []byte("collection,seller,price,mintAddress\ndegods,seller,notaprice,mint\n"),
+			want:      []models.Listing{},
+			expectErr: true,
+		},
+	}
+
+	// Iterate through tests and run them
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ans, err := tt.unmarshal(tt.input)
+
+			// Compare answer with wanted data
+			if !reflect.DeepEqual(ans, tt.want) {
+				t.Errorf("Got %v, wanted %v", ans, tt.want)
+			}
+
+			// Check for faulty error cases
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, got nil.")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestUnmarshalStatsJSON calls formatter.UnmarshalStatsJSON with valid and invalid input, checking that prices are converted to SOL
+func TestUnmarshalStatsJSON(t *testing.T) {
+	// Create test table
+	var tests = []struct {
+		name      string
+		input     []byte
+		want      models.CollectionStats
+		expectErr bool
+	}{
+		{
+			name:  "Valid",
+			input: []byte(`{"symbol":"degods","floorPrice":5388500000,"listedCount":412,"avgPrice24hr":5612345678.5,"volumeAll":1234567000000000}`),
+			want:  models.CollectionStats{Collection: "degods", FloorPrice: 5.3885, ListedCount: 412, AvgPrice24h: 5.6123456785, TotalVolume: 1234567},
+		},
+		{ // Fields missing from the response stay zero
+			name:  "Missing fields",
+			input: []byte(`{"symbol":"degods","floorPrice":1000000000}`),
+			want:  models.CollectionStats{Collection: "degods", FloorPrice: 1},
+		},
+		{
+			name:      "Invalid",
+			input:     []byte(`{"symbol":`),
+			want:      models.CollectionStats{},
+			expectErr: true,
+		},
+	}
+
+	// Iterate through tests and run them
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ans, err := UnmarshalStatsJSON(tt.input)
+
+			// Compare answer with wanted data
+			if ans != tt.want {
+				t.Errorf("Got %+v, wanted %+v", ans, tt.want)
+			}
+
+			// Check for faulty error cases
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, got nil.")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestUnmarshalActivitiesJSON calls formatter.UnmarshalActivitiesJSON with valid and invalid input, checking times and collection fallback
+func TestUnmarshalActivitiesJSON(t *testing.T) {
+	// Create test table
+	var tests = []struct {
+		name      string
+		input     []byte
+		symbol    string // Fetched collection
+		want      []models.Activity
+		expectErr bool
+	}{
+		{
+			name:   "Valid",
+			symbol: "degods",
+			input:  []byte(`[{"signature":"sig1","type":"buyNow","source":"magiceden_v2","tokenMint":"mint1","collection":"degods","slot":1,"blockTime":1704164645,"buyer":"buyer1","buyerReferral":"","seller":"seller1","sellerReferral":"","price":5.3885}]`),
+			want: []models.Activity{{Collection: "degods", Type: "buyNow", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Price: 5.3885,
+				Seller: "seller1", Buyer: "buyer1", Mint: "mint1", Signature: "sig1", Source: "magiceden_v2"}},
+		},
+		{ // Activities without a collection get the fetched symbol
+			name:   "Missing collection",
+			symbol: "y00ts",
+			input:  []byte(`[{"signature":"sig2","type":"bid","blockTime":0}]`),
+			want:   []models.Activity{{Collection: "y00ts", Type: "bid", Time: time.Unix(0, 0).UTC(), Signature: "sig2"}},
+		},
+		{
+			name:      "Invalid",
+			input:     []byte(`[{"signature":`),
+			want:      []models.Activity{},
+			expectErr: true,
+		},
+	}
+
+	// Iterate through tests and run them
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ans, err := UnmarshalActivitiesJSON(tt.input, tt.symbol)
+
+			// Compare answer with wanted data
+			if !reflect.DeepEqual(ans, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", ans, tt.want)
+			}
+
+			// Check for faulty error cases
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, got nil.")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestUnmarshalTokenJSON calls formatter.UnmarshalTokenJSON with valid and invalid input, checking attribute values of every JSON type
+func TestUnmarshalTokenJSON(t *testing.T) {
+	// Create test table
+	var tests = []struct {
+		name      string
+		input     []byte
+		want      models.TokenMetadata
+		expectErr bool
+	}{
+		{
+			name:  "Valid",
+			input: []byte(`{"mintAddress":"mint1","name":"DeGod #1","image":"https://example.com/1.png","attributes":[{"trait_type":"Background","value":"Red"},{"trait_type":"Level","value":7},{"trait_type":"Legendary","value":true},{"trait_type":"Empty","value":null}]}`),
+			want: models.TokenMetadata{Mint: "mint1", Name: "DeGod #1", Image: "https://example.com/1.png", Attributes: []models.Attribute{
+				{TraitType: "Background", Value: "Red"}, {TraitType: "Level", Value: "7"}, {TraitType: "Legendary", Value: "true"}, {TraitType: "Empty", Value: ""},
+			}},
+		},
+		{ // Tokens without attributes keep them nil
+			name:  "No attributes",
+			input: []byte(`{"mintAddress":"mint2","name":"DeGod #2"}`),
+			want:  models.TokenMetadata{Mint: "mint2", Name: "DeGod #2"},
+		},
+		{
+			name:      "Invalid",
+			input:     []byte(`{"mintAddress":`),
+			want:      models.TokenMetadata{},
+			expectErr: true,
+		},
+	}
+
+	// Iterate through tests and run them
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ans, err := UnmarshalTokenJSON(tt.input)
+
+			// Compare answer with wanted data
+			if !reflect.DeepEqual(ans, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", ans, tt.want)
+			}
+
+			// Check for faulty error cases
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, got nil.")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
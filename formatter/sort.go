@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"cmp"
+	"fmt"
+	"mantas9/listings/models"
+	"slices"
+	"strings"
+)
+
+// Orders listings can be sorted in with --sort
+var SortOrders = []string{"price", "rank", "price-per-rank"}
+
+// Returns a copy of listings across every collection, sorted by:
+//   - price: cheapest first
+//   - rank: rarest first
+//   - price-per-rank: lowest price × rank first, so cheap rare listings come before both expensive rare and cheap common ones
+//
+// Unranked listings come last when sorting by rank or price-per-rank. Ties keep their original order
+func SortListings(listings []models.Listing, order string) ([]models.Listing, error) {
+	var compare func(a, b models.Listing) int
+
+	switch order {
+	case "price":
+		compare = func(a, b models.Listing) int { return cmp.Compare(a.Price, b.Price) }
+	case "rank":
+		compare = func(a, b models.Listing) int {
+			return compareRanked(a, b, func(l models.Listing) float64 { return float64(l.Rank) })
+		}
+	case "price-per-rank":
+		compare = func(a, b models.Listing) int {
+			return compareRanked(a, b, func(l models.Listing) float64 { return l.Price * float64(l.Rank) })
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort order %q, expected %s", order, strings.Join(SortOrders, ", "))
+	}
+
+	return slices.SortedStableFunc(slices.Values(listings), compare), nil
+}
+
+// Compares ranked listings by value, putting unranked listings last
+func compareRanked(a, b models.Listing, value func(models.Listing) float64) int {
+	if ranked := cmp.Compare(rankless(a), rankless(b)); ranked != 0 {
+		return ranked
+	}
+
+	return cmp.Compare(value(a), value(b))
+}
+
+// Returns 1 for unranked listings, 0 otherwise
+func rankless(listing models.Listing) int {
+	if listing.Rank <= 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,61 @@
+package formatter
+
+import (
+	"mantas9/listings/models"
+	"slices"
+	"testing"
+)
+
+// TestSortListings sorts listings of several collections by every order, checking where unranked listings go
+func TestSortListings(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Mint: "common", Price: 1, Rank: 5000},
+		{Collection: "degods", Mint: "unranked", Price: 0.5},
+		{Collection: "y00ts", Mint: "rare", Price: 5, Rank: 10},
+		{Collection: "y00ts", Mint: "rarest", Price: 100, Rank: 1},
+		{Collection: "degods", Mint: "mid", Price: 2, Rank: 300},
+	}
+
+	// Test table
+	var tests = []struct {
+		order     string
+		want      []string // Wanted mints, in order
+		expectErr bool
+	}{
+		{order: "price", want: []string{"unranked", "common", "mid", "rare", "rarest"}},
+		{order: "rank", want: []string{"rarest", "rare", "mid", "common", "unranked"}},
+		{order: "price-per-rank", want: []string{"rare", "rarest", "mid", "common", "unranked"}}, // 50, 100, 600, 5000
+		{order: "seller", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			got, err := SortListings(input, tt.order)
+
+			// Check for faulty error cases
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got nil.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Compare order
+			mints := []string{}
+			for _, listing := range got {
+				mints = append(mints, listing.Mint)
+			}
+			if !slices.Equal(mints, tt.want) {
+				t.Errorf("Got %v, wanted %v", mints, tt.want)
+			}
+		})
+	}
+
+	// The input is copied, not sorted in place
+	if input[0].Mint != "common" {
+		t.Errorf("Input listings were sorted in place")
+	}
+}
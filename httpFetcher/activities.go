@@ -0,0 +1,126 @@
+package httpfetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Activity types which can be filtered with GetActivitiesOpts.Types
+var ActivityTypes = []string{"list", "delist", "buyNow", "bid"}
+
+// GetActivities call parameters
+type GetActivitiesOpts struct {
+	Symbol string   // Collection symbol
+	Limit  int64    // Limit of total activities to be fetched (only counting activities of Types)
+	Offset int64    // Amount of activities to skip
+	Types  []string // Activity types to keep, e.g. buyNow (default - every type)
+	All    bool     // Page through the whole history (capped by Limit if set)
+}
+
+// Fields used to identify and filter an activity while paging
+type activityKey struct {
+	Signature string `json:"signature"` // Transaction signature
+	Type      string `json:"type"`      // Activity type
+	TokenMint string `json:"tokenMint"` // Token mint address
+}
+
+func GetActivities(ctx context.Context, opts GetActivitiesOpts) ([]byte, error) { // Base GetActivities function call, using DefaultClient
+	return DefaultClient.GetActivities(ctx, opts)
+}
+
+// Fetches the activities of a collection (newest first) through the client. The endpoint returns every type,
+// so Types are filtered after fetching and pages are fetched until Limit activities of those types are found.
+// If ctx is cancelled while paging, the activities fetched so far are returned along with the context error
+func (c *Client) GetActivities(ctx context.Context, opts GetActivitiesOpts) ([]byte, error) {
+	// Single unfiltered page
+	if !opts.All && opts.Limit <= MaxPageSize && len(opts.Types) == 0 {
+		return c.getActivitiesPage(ctx, opts)
+	}
+
+	p := pager{
+		what:      "activities",
+		limit:     opts.Limit,
+		offset:    opts.Offset,
+		fullPages: len(opts.Types) > 0,
+		fetch: func(ctx context.Context, limit, offset int64) ([]byte, error) {
+			pageOpts := opts
+			pageOpts.Limit = limit
+			pageOpts.Offset = offset
+
+			return c.getActivitiesPage(ctx, pageOpts)
+		},
+		key: activityID,
+	}
+
+	// Keep activities of the given types
+	if len(opts.Types) > 0 {
+		p.keep = func(item json.RawMessage) (bool, error) {
+			key := activityKey{}
+			if err := json.Unmarshal(item, &key); err != nil { // Error check
+				return false, fmt.Errorf("cannot parse activity: %w", err)
+			}
+
+			return slices.Contains(opts.Types, key.Type), nil
+		}
+	}
+
+	// A single filtered page unless paging was requested
+	if !opts.All && opts.Limit == 0 {
+		p.maxPages = 1
+	}
+
+	return p.run(ctx)
+}
+
+func (c *Client) getActivitiesPage(ctx context.Context, opts GetActivitiesOpts) ([]byte, error) { // Fetches a single page of activities
+
+	// URL To API
+	url, err := c.formActivitiesURL(opts)
+
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	return c.get(ctx, url, opts.Symbol, "")
+}
+
+func (c *Client) formActivitiesURL(opts GetActivitiesOpts) (string, error) { // Forms the magicEden API URL of collection activities
+	// Handle empty symbol
+	if opts.Symbol == "" {
+		return "", fmt.Errorf("cannot form URL to API: %w: "+`"`+"%v"+`"`, ErrInvalidSymbol, opts.Symbol)
+	}
+
+	res := fmt.Sprintf("%s/v2/collections/%s/activities", c.baseURL, opts.Symbol)
+
+	// Handle extra parameters
+	query := url.Values{}
+	if opts.Limit != 0 {
+		query.Set("limit", fmt.Sprint(opts.Limit))
+	}
+	if opts.Offset != 0 {
+		query.Set("offset", fmt.Sprint(opts.Offset))
+	}
+	if len(query) > 0 {
+		res += "?" + query.Encode()
+	}
+
+	return res, nil
+}
+
+func activityID(activity json.RawMessage) (string, error) { // Returns a key identifying a raw activity
+	key := activityKey{}
+
+	if err := json.Unmarshal(activity, &key); err != nil { // Error check
+		return "", fmt.Errorf("cannot parse activity: %w", err)
+	}
+
+	// Activities without a signature cannot be told apart
+	if key.Signature == "" {
+		return "", nil
+	}
+
+	return key.Signature + "/" + key.Type + "/" + key.TokenMint, nil
+}
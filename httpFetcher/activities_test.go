@@ -0,0 +1,138 @@
+package httpfetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// TestFormActivitiesURL runs formActivitiesURL with option variations and validates the API URL
+func TestFormActivitiesURL(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name      string
+		options   GetActivitiesOpts
+		want      string
+		expectErr bool
+	}{
+		{name: "Default", options: GetActivitiesOpts{Symbol: "degods"}, want: "https://api-mainnet.magiceden.dev/v2/collections/degods/activities"},
+		{name: "Limit Offset", options: GetActivitiesOpts{Symbol: "degods", Limit: 5, Offset: 10}, want: "https://api-mainnet.magiceden.dev/v2/collections/degods/activities?limit=5&offset=10"},
+		{name: "Types are not sent", options: GetActivitiesOpts{Symbol: "degods", Types: []string{"bid"}}, want: "https://api-mainnet.magiceden.dev/v2/collections/degods/activities"},
+		{name: "Empty", options: GetActivitiesOpts{}, expectErr: true},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ans, err := NewClient(ClientOpts{}).formActivitiesURL(tt.options)
+
+			// Error handling scenarios
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, but got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+
+			if ans != tt.want {
+				t.Errorf("Got %s, wanted %s", ans, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientGetActivities pages through a mock activity history and validates type filtering, de-duplication and page sizes
+func TestClientGetActivities(t *testing.T) {
+	types := []string{"list", "delist", "buyNow", "bid", "cancelBid"}
+
+	// Mock history of 250 activities cycling through types, where the activity at offset 100 repeats the last activity of the first page
+	history := []string{}
+	for i := 0; i < 250; i++ {
+		n := i
+		if i == 100 {
+			n = 99
+		}
+		history = append(history, fmt.Sprintf(`{"signature":"sig%d","type":"%s","tokenMint":"mint%d"}`, n, types[n%len(types)], n))
+	}
+
+	// Test table
+	var tests = []struct {
+		name      string
+		options   GetActivitiesOpts
+		wantCount int   // Wanted amount of merged activities
+		wantLimit []int // Wanted page sizes, 0 if not sent
+	}{
+		{name: "Single page", options: GetActivitiesOpts{Symbol: "degods"}, wantCount: 100, wantLimit: []int{0}},
+		{name: "Limit within page size", options: GetActivitiesOpts{Symbol: "degods", Limit: 20}, wantCount: 20, wantLimit: []int{20}},
+		{name: "All", options: GetActivitiesOpts{Symbol: "degods", All: true}, wantCount: 249, wantLimit: []int{100, 100, 100}},
+		{name: "Type, single page", options: GetActivitiesOpts{Symbol: "degods", Types: []string{"buyNow"}}, wantCount: 20, wantLimit: []int{100}},
+		{name: "Type with limit", options: GetActivitiesOpts{Symbol: "degods", Types: []string{"buyNow"}, Limit: 30}, wantCount: 30, wantLimit: []int{100, 100}},
+		{name: "Types, all", options: GetActivitiesOpts{Symbol: "degods", Types: []string{"buyNow", "bid"}, All: true}, wantCount: 100, wantLimit: []int{100, 100, 100}},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := []int{} // Page sizes requested by the client
+
+			// Mock paginated API
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v2/collections/degods/activities" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limits = append(limits, limit)
+
+				// Slice the requested page, 100 activities by default
+				if limit == 0 {
+					limit = MaxPageSize
+				}
+				end := min(offset+limit, len(history))
+				page := history[min(offset, end):end]
+
+				fmt.Fprintf(w, "[%s]", joinJSON(page))
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			body, err := NewClient(ClientOpts{BaseURL: server.URL, RequestsPerSecond: -1}).GetActivities(context.Background(), tt.options)
+
+			if err != nil { // Error check
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			// Validate merged activities
+			activities := []activityKey{}
+			if err := json.Unmarshal(body, &activities); err != nil {
+				t.Fatalf("Merged body is not a JSON array: %v", err)
+			}
+			if len(activities) != tt.wantCount {
+				t.Errorf("Got %d activities, wanted %d", len(activities), tt.wantCount)
+			}
+
+			// Check for duplicates and filtered types
+			seen := map[string]bool{}
+			for _, activity := range activities {
+				if seen[activity.Signature] {
+					t.Errorf("Duplicate activity %s", activity.Signature)
+				}
+				seen[activity.Signature] = true
+
+				if len(tt.options.Types) > 0 && activity.Type != "buyNow" && activity.Type != "bid" {
+					t.Errorf("Activity %s of type %s was not filtered", activity.Signature, activity.Type)
+				}
+			}
+
+			// Validate requested pages
+			if fmt.Sprint(limits) != fmt.Sprint(tt.wantLimit) {
+				t.Errorf("Got page sizes %v, wanted %v", limits, tt.wantLimit)
+			}
+		})
+	}
+}
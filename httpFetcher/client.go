@@ -0,0 +1,111 @@
+package httpfetcher
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default MagicEden API base URL
+const DefaultBaseURL = "https://api-mainnet.magiceden.dev"
+
+// Default timeout of a single HTTP request
+const DefaultTimeout = 10 * time.Second
+
+// NewClient call parameters
+type ClientOpts struct {
+	BaseURL           string        // API base URL (default - DefaultBaseURL)
+	HTTPClient        *http.Client  // HTTP client used for every request (default - a new client with Timeout)
+	Headers           http.Header   // Extra headers added to every request
+	Timeout           time.Duration // Timeout of a single request, ignored if HTTPClient is set (default - DefaultTimeout)
+	RequestsPerSecond float64       // Requests per second let through by the rate limiter, negative to disable (default - DefaultRequestsPerSecond)
+	Burst             int           // Requests which can be sent at once before throttling (default - DefaultBurst)
+	Retry             *RetryPolicy  // Policy for retrying failed requests (default - DefaultRetryPolicy)
+	Logger            *slog.Logger  // Logger for throttling decisions (default - discards logs)
+}
+
+// MagicEden API client. A Client is safe for concurrent use
+type Client struct {
+	baseURL    string       // API base URL without a trailing slash
+	httpClient *http.Client // Shared HTTP client
+	headers    http.Header  // Extra request headers
+	limiter    *rateLimiter // Shared rate limiter, nil if disabled
+	retry      RetryPolicy  // Retry policy
+	logger     *slog.Logger // Logger
+}
+
+// Client used by the package-level fetch functions
+var DefaultClient = NewClient(ClientOpts{})
+
+// Creates a new API client, filling unset options with defaults
+func NewClient(opts ClientOpts) *Client {
+	// Base URL
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	// HTTP client
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		timeout := opts.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	// Copy headers so the caller can't modify them after creation
+	headers := http.Header{}
+	for key, values := range opts.Headers {
+		for _, value := range values {
+			headers.Add(key, value)
+		}
+	}
+
+	// Rate limiter
+	var limiter *rateLimiter
+	rate := opts.RequestsPerSecond
+	if rate == 0 {
+		rate = DefaultRequestsPerSecond
+	}
+	if rate > 0 {
+		burst := opts.Burst
+		if burst == 0 {
+			burst = DefaultBurst
+		}
+
+		limiter = newRateLimiter(rate, burst)
+	}
+
+	// Retry policy
+	retry := DefaultRetryPolicy
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	// Logger
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+		headers:    headers,
+		limiter:    limiter,
+		retry:      retry,
+		logger:     logger,
+	}
+}
+
+// Returns the base URL the client sends requests to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
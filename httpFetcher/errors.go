@@ -0,0 +1,81 @@
+package httpfetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Returned when the requested collection does not exist
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// Returned when the requested token does not exist
+var ErrTokenNotFound = errors.New("token not found")
+
+// Returned when the API kept rate limiting requests after every retry
+var ErrRateLimited = errors.New("rate limited by API")
+
+// Returned when a collection symbol is empty or malformed
+var ErrInvalidSymbol = errors.New("invalid collection symbol")
+
+// Returned when a token mint address is empty
+var ErrInvalidMint = errors.New("invalid mint address")
+
+// Maximum amount of response body bytes kept in an APIError
+const maxErrorBody = 512
+
+// Error returned when the API responds with a non-OK status code
+type APIError struct {
+	StatusCode int    // Response status code
+	Status     string // Response status text
+	Body       string // Start of the response body
+	Symbol     string // Collection symbol of the request, empty for token requests
+	Mint       string // Token mint of the request, empty for collection requests
+	URL        string // Requested URL
+}
+
+// Creates an APIError from a non-OK response, reading the start of its body
+func newAPIError(res *http.Response, body []byte, symbol, mint, url string) *APIError {
+	// Trim long bodies
+	if len(body) > maxErrorBody {
+		body = append(body[:maxErrorBody:maxErrorBody], "..."...)
+	}
+
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Body:       string(body),
+		Symbol:     symbol,
+		Mint:       mint,
+		URL:        url,
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("MagicEden API returned %s for collection %q (%s)", e.Status, e.Symbol, e.URL)
+	if e.Mint != "" {
+		msg = fmt.Sprintf("MagicEden API returned %s for token %q (%s)", e.Status, e.Mint, e.URL)
+	}
+
+	// Add response body if the API explained the error
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+
+	return msg
+}
+
+// Maps the status code to a sentinel error, so APIError works with errors.Is
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		if e.Mint != "" {
+			return ErrTokenNotFound
+		}
+		return ErrCollectionNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package httpfetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestClientAPIErrors runs GetListings against failing servers and validates the returned typed errors
+func TestClientAPIErrors(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name       string
+		symbol     string
+		status     int    // Status code returned by the server
+		body       string // Body returned by the server
+		wantIs     error  // Sentinel error the result should match, nil if none
+		wantStatus int    // Wanted APIError status code, 0 if the error is not an APIError
+	}{
+		{
+			name:       "Not found",
+			symbol:     "degodz",
+			status:     http.StatusNotFound,
+			body:       `{"msg":"collection not found"}`,
+			wantIs:     ErrCollectionNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Rate limited",
+			symbol:     "degods",
+			status:     http.StatusTooManyRequests,
+			body:       "",
+			wantIs:     ErrRateLimited,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "Server error",
+			symbol:     "degods",
+			status:     http.StatusInternalServerError,
+			body:       strings.Repeat("x", 1000),
+			wantIs:     nil,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Invalid symbol",
+			symbol:     "",
+			wantIs:     ErrInvalidSymbol,
+			wantStatus: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Mock server, always failing
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{BaseURL: server.URL, RequestsPerSecond: -1, Retry: &RetryPolicy{MaxAttempts: 1}})
+
+			_, err := client.GetListings(context.Background(), GetListingsOpts{Symbol: tt.symbol})
+
+			if err == nil { // Every scenario fails
+				t.Fatalf("Expected error, but got nil")
+			}
+
+			// Sentinel errors
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("Expected error to match %v, got %v", tt.wantIs, err)
+			}
+			if tt.wantIs != ErrCollectionNotFound && errors.Is(err, ErrCollectionNotFound) {
+				t.Errorf("Unexpected ErrCollectionNotFound match: %v", err)
+			}
+
+			// APIError details
+			apiErr := &APIError{}
+			isAPIError := errors.As(err, &apiErr)
+
+			if tt.wantStatus == 0 {
+				if isAPIError {
+					t.Errorf("Unexpected APIError: %v", err)
+				}
+				return
+			}
+			if !isAPIError {
+				t.Fatalf("Expected APIError, got %T: %v", err, err)
+			}
+
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("Got status %d, wanted %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.Symbol != tt.symbol {
+				t.Errorf("Got symbol %q, wanted %q", apiErr.Symbol, tt.symbol)
+			}
+			if !strings.HasPrefix(apiErr.URL, server.URL) {
+				t.Errorf("Got URL %s, wanted a URL of the mock server", apiErr.URL)
+			}
+			if len(apiErr.Body) > maxErrorBody+3 || !strings.HasPrefix(tt.body, strings.TrimSuffix(apiErr.Body, "...")) {
+				t.Errorf("Got body %q, wanted the start of %q", apiErr.Body, tt.body)
+			}
+		})
+	}
+}
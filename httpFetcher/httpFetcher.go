@@ -1,6 +1,7 @@
 package httpfetcher
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,47 +12,83 @@ import (
 type GetListingsOpts struct {
 	Symbol   string  // Collection symbol
 	Limit    int64   // Limit of total collection listings to be fetched
+	Offset   int64   // Amount of listings to skip
 	MinPrice float64 // Minimum price of listings
 	MaxPrice float64 // Maximum price of listings
 	Desc     bool    // Sort results by price descending
+	All      bool    // Page through the whole collection (capped by Limit if set)
+	MinRank  int64   // Minimum rarity rank of listings, filtered after fetching (see models.RarityJSON.Rank)
+	MaxRank  int64   // Maximum rarity rank of listings, filtered after fetching
+	MaxPages int     // Maximum amount of pages fetched while paging, 0 for no limit
 }
 
-func GetListings(opts GetListingsOpts) ([]byte, error) { // Base GetListings function call
+// Tells whether listings are filtered by rarity rank
+func (opts GetListingsOpts) rankFiltered() bool {
+	return opts.MinRank > 0 || opts.MaxRank > 0
+}
+
+func GetListings(ctx context.Context, opts GetListingsOpts) ([]byte, error) { // Base GetListings function call, using DefaultClient
+	return DefaultClient.GetListings(ctx, opts)
+}
+
+// Fetches listings of a collection through the client. If ctx is cancelled while paging,
+// the listings fetched so far are returned along with the context error
+func (c *Client) GetListings(ctx context.Context, opts GetListingsOpts) ([]byte, error) {
+
+	// Page through results if more than a single page was requested, or to fill the limit with listings of the given ranks
+	if opts.All || opts.Limit > MaxPageSize || opts.rankFiltered() {
+		return c.getListingPages(ctx, opts)
+	}
+
+	return c.getListingsPage(ctx, opts)
+}
+
+func (c *Client) getListingsPage(ctx context.Context, opts GetListingsOpts) ([]byte, error) { // Fetches a single page of listings
 
 	// URL To API
-	url, err := formListingsURL(opts)
+	url, err := c.formListingsURL(opts)
 
 	if err != nil { // Error check
 		return nil, err
 	}
 
+	return c.get(ctx, url, opts.Symbol, "")
+}
+
+func (c *Client) get(ctx context.Context, url, symbol, mint string) ([]byte, error) { // Fetches the body of an API endpoint of a collection or a token, returning an *APIError on unexpected statuses
+
 	// Execute HTTP request
-	res, err := httpRequest(url)
+	res, err := c.httpRequest(ctx, url)
 
 	if err != nil { // Error check
 		return nil, err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP request did not return OK (200)")
-	}
-
 	// Close body reader when done
 	defer res.Body.Close()
+
 	// Read fetched data
 	body, err := io.ReadAll(res.Body)
 
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK { // API error
+		return nil, newAPIError(res, body, symbol, mint, url)
+	}
+
 	// Return result
-	return body, err
+	return body, nil
 }
 
-func formListingsURL(opts GetListingsOpts) (string, error) { // Forms the magicEden API URL according to input parameters
+func (c *Client) formListingsURL(opts GetListingsOpts) (string, error) { // Forms the magicEden API URL according to input parameters
 	// Handle empty symbol
 	if opts.Symbol == "" {
-		return "", fmt.Errorf("cannot form URL to API: NFT Symbol is invalid: "+`"`+"%v"+`"`, opts.Symbol)
+		return "", fmt.Errorf("cannot form URL to API: %w: "+`"`+"%v"+`"`, ErrInvalidSymbol, opts.Symbol)
 	}
 
-	url := fmt.Sprintf("https://api-mainnet.magiceden.dev/v2/collections/%s/listings", opts.Symbol)
+	url := fmt.Sprintf("%s/v2/collections/%s/listings", c.baseURL, opts.Symbol)
 
 	// Handle extra parameters
 	paramCnt := 0        // Amount of parameters added
@@ -59,6 +96,20 @@ func formListingsURL(opts GetListingsOpts) (string, error) { // Forms the magicE
 		url += fmt.Sprintf("?limit=%d", opts.Limit) // Add limit option
 		paramCnt++
 	}
+	if opts.Offset != 0 { // Listing offset
+		// Check if there are already options listed
+		if paramCnt != 0 {
+			url += "&" // Add & for next param
+		} else {
+			url += "?" // Add ? because this is the first param
+		}
+
+		// Add Offset argument
+		url += fmt.Sprintf("offset=%d", opts.Offset)
+
+		// Param counter
+		paramCnt++
+	}
 	if opts.MinPrice != 0 { // Min price of listing
 		// Check if there are already options listed
 		if paramCnt != 0 {
@@ -105,24 +156,117 @@ func formListingsURL(opts GetListingsOpts) (string, error) { // Forms the magicE
 	return url, nil
 }
 
-func httpRequest(url string) (*http.Response, error) { // Performs a HTTP request and returns the output
+func (c *Client) httpRequest(ctx context.Context, url string) (*http.Response, error) { // Performs a rate limited HTTP GET request, retrying transient failures
+	return c.doRequest(ctx, http.MethodGet, url)
+}
+
+func (c *Client) doRequest(ctx context.Context, method, url string) (*http.Response, error) { // Performs a rate limited HTTP request, retrying transient failures of idempotent requests
+	// Only idempotent requests may be sent more than once
+	attempts := c.retry.MaxAttempts
+	if !isIdempotent(method) {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Wait for the rate limiter
+		if err := c.throttle(ctx, url); err != nil {
+			return nil, err
+		}
+
+		// Execute HTTP request
+		res, err := c.sendRequest(ctx, method, url)
+
+		// Return successful responses, permanent failures and the last attempt
+		if attempt >= attempts {
+			return res, err
+		}
+		if err != nil && (ctx.Err() != nil || !retryableError(err)) { // Cancelled requests are never retried
+			return nil, err
+		}
+		if err == nil && !c.retry.retryStatus(res.StatusCode) {
+			return res, nil
+		}
+
+		// Delay before the next attempt
+		delay := c.retry.backoff(attempt, jitterRandom())
+
+		if err != nil { // Transient request error
+			c.logger.Warn("request failed, retrying", "url", url, "error", err, "attempt", attempt, "delay", delay)
+		} else { // Retryable status code
+			// Back off for at least as long as the API asked
+			if after, ok := retryAfter(res.Header, time.Now()); ok && after > delay {
+				delay = after
+			}
+			res.Body.Close()
+
+			if res.StatusCode == http.StatusTooManyRequests { // Pause every request sharing the limiter
+				c.logger.Warn("rate limited by API, backing off", "url", url, "attempt", attempt, "delay", delay)
+
+				if c.limiter != nil {
+					c.limiter.pause(delay)
+					continue
+				}
+			} else {
+				c.logger.Warn("request failed, retrying", "url", url, "status", res.StatusCode, "attempt", attempt, "delay", delay)
+			}
+		}
+
+		if err := sleep(ctx, delay); err != nil { // Wait before the next attempt
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) throttle(ctx context.Context, url string) error { // Blocks until the rate limiter lets a request through or ctx is done
+	if c.limiter == nil { // Rate limiting disabled
+		return ctx.Err()
+	}
+
+	wait := c.limiter.reserve()
+
+	if wait > 0 {
+		c.logger.Debug("rate limiter delaying request", "url", url, "wait", wait)
+	}
+
+	return sleep(ctx, wait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error { // Sleeps for the given duration, returning early with the context error if ctx is done
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) sendRequest(ctx context.Context, method, url string) (*http.Response, error) { // Performs a single HTTP request and returns the output
 	// Create HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 
 	if err != nil { // Error check
 		return nil, err
 	}
 
-	// Add JSON header to request
-	req.Header.Add("accept", "application/json")
-
-	// Http client with timeout handling
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	// Add client headers to request
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
 	}
 
-	// Execute HTTP request
-	res, err := client.Do(req)
+	// Add JSON header to request
+	req.Header.Set("accept", "application/json")
+
+	// Execute HTTP request with the shared client
+	res, err := c.httpClient.Do(req)
 
 	if err != nil { // Error check
 		return nil, err
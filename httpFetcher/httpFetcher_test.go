@@ -1,9 +1,13 @@
 package httpfetcher
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -79,7 +83,7 @@ func TestHttpRequest(t *testing.T) {
 			server := httptest.NewServer(tt.handler)
 			defer server.Close() // Close server at the end of scope
 
-			res, err := httpRequest(server.URL)
+			res, err := NewClient(ClientOpts{Retry: &RetryPolicy{MaxAttempts: 1}}).httpRequest(context.Background(), server.URL) // Single attempt
 
 			// Error handling scenarios
 			if tt.expectErr && err == nil {
@@ -242,6 +246,26 @@ func TestFormListingsURL(t *testing.T) {
 			want:      "https://api-mainnet.magiceden.dev/v2/collections/degods/listings?max_price=10.000000&sort_direction=desc",
 			expectErr: false,
 		},
+		{
+			name: "Limit Offset",
+			options: GetListingsOpts{
+				Symbol: "degods",
+				Limit:  5,
+				Offset: 10,
+			},
+			want:      "https://api-mainnet.magiceden.dev/v2/collections/degods/listings?limit=5&offset=10",
+			expectErr: false,
+		},
+		{
+			name: "Offset MinPrice",
+			options: GetListingsOpts{
+				Symbol:   "degods",
+				Offset:   10,
+				MinPrice: 2,
+			},
+			want:      "https://api-mainnet.magiceden.dev/v2/collections/degods/listings?offset=10&min_price=2.000000",
+			expectErr: false,
+		},
 		{
 			name: "Desc",
 			options: GetListingsOpts{
@@ -259,7 +283,7 @@ func TestFormListingsURL(t *testing.T) {
 
 		// Run test
 		t.Run(testname, func(t *testing.T) {
-			url, err := formListingsURL(tt.options) // Run function
+			url, err := NewClient(ClientOpts{}).formListingsURL(tt.options) // Run function
 
 			// Check for error mismatches
 			if tt.expectErr && err == nil {
@@ -276,3 +300,355 @@ func TestFormListingsURL(t *testing.T) {
 		})
 	}
 }
+
+// TestClientGetListings runs the full GetListings path against a mock server through a Client with a custom base URL and headers
+func TestClientGetListings(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name      string
+		handler   http.HandlerFunc
+		options   GetListingsOpts
+		want      string
+		expectErr bool
+	}{
+		{
+			name: "successful request",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Validate request path and query
+				if r.URL.Path != "/v2/collections/degods/listings" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != "limit=5" {
+					t.Errorf("Unexpected query %s", r.URL.RawQuery)
+				}
+
+				// Check for client headers
+				if r.Header.Get("Authorization") != "Bearer key" {
+					t.Errorf("Missing Authorization header")
+				}
+				if r.Header.Get("accept") != "application/json" {
+					t.Errorf("Missing Accept header")
+				}
+
+				w.Write([]byte(`[{"seller":"seller1","price":1.5}]`))
+			},
+			options:   GetListingsOpts{Symbol: "degods", Limit: 5},
+			want:      `[{"seller":"seller1","price":1.5}]`,
+			expectErr: false,
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			options:   GetListingsOpts{Symbol: "degods"},
+			want:      "",
+			expectErr: true,
+		},
+		{
+			name: "empty symbol",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("Request should not be sent")
+			},
+			options:   GetListingsOpts{},
+			want:      "",
+			expectErr: true,
+		},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		testName := tt.name
+
+		t.Run(testName, func(t *testing.T) {
+
+			// Mock HTTP server
+			server := httptest.NewServer(tt.handler)
+			defer server.Close() // Close server at the end of scope
+
+			// Client pointed at the mock server (trailing slash should be trimmed)
+			client := NewClient(ClientOpts{
+				BaseURL:    server.URL + "/",
+				HTTPClient: server.Client(),
+				Headers:    http.Header{"Authorization": {"Bearer key"}},
+				Retry:      &RetryPolicy{MaxAttempts: 1},
+			})
+
+			body, err := client.GetListings(context.Background(), tt.options)
+
+			// Error handling scenarios
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, but got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+
+			// Validate returned body
+			if string(body) != tt.want {
+				t.Errorf("Got %s, wanted %s", string(body), tt.want)
+			}
+		})
+	}
+}
+
+// TestNewClientDefaults checks that NewClient fills unset options with defaults
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient(ClientOpts{})
+
+	// Default base URL
+	if client.BaseURL() != DefaultBaseURL {
+		t.Errorf("Got base URL %s, wanted %s", client.BaseURL(), DefaultBaseURL)
+	}
+
+	// Default timeout
+	if client.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("Got timeout %v, wanted %v", client.httpClient.Timeout, DefaultTimeout)
+	}
+
+	// Custom timeout
+	client = NewClient(ClientOpts{Timeout: time.Second})
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("Got timeout %v, wanted %v", client.httpClient.Timeout, time.Second)
+	}
+}
+
+// TestClientGetListingsPagination runs GetListings against a mock paginated collection and validates the merged result
+func TestClientGetListingsPagination(t *testing.T) {
+
+	// Mock collection of 250 listings, where the listing at offset 100 repeats the last listing of the first page.
+	// Listings are ranked by offset (rank 1 at offset 0), except every fifth one
+	collection := []string{}
+	for i := 0; i < 250; i++ {
+		mint := fmt.Sprintf("mint%d", i)
+		if i == 100 {
+			mint = "mint99"
+		}
+		rarity := fmt.Sprintf(`{"moonrank":{"rank":%d}}`, i+1)
+		if i%5 == 4 {
+			rarity = `{}`
+		}
+		collection = append(collection, fmt.Sprintf(`{"tokenMint":"%s","rarity":%s,"token":{"mintAddress":"%s"}}`, mint, rarity, mint))
+	}
+
+	// Test table
+	var tests = []struct {
+		name      string
+		options   GetListingsOpts
+		wantCount int   // Wanted amount of merged listings
+		wantPages int   // Wanted amount of requests
+		wantLimit []int // Wanted page sizes
+	}{
+		{
+			name:      "All",
+			options:   GetListingsOpts{Symbol: "degods", All: true},
+			wantCount: 249,
+			wantPages: 3,
+			wantLimit: []int{100, 100, 100},
+		},
+		{
+			name:      "Limit over page size",
+			options:   GetListingsOpts{Symbol: "degods", Limit: 150},
+			wantCount: 150,
+			wantPages: 3, // Duplicate at offset 100 is dropped, so one more listing is fetched
+			wantLimit: []int{100, 50, 1},
+		},
+		{
+			name:      "All with limit",
+			options:   GetListingsOpts{Symbol: "degods", All: true, Limit: 120},
+			wantCount: 120,
+			wantPages: 3,
+			wantLimit: []int{100, 20, 1},
+		},
+		{ // A single full page is filtered without a limit
+			name:      "Max rank",
+			options:   GetListingsOpts{Symbol: "degods", MaxRank: 50},
+			wantCount: 40,
+			wantPages: 1,
+			wantLimit: []int{100},
+		},
+		{ // Full pages are fetched until enough listings match
+			name:      "Rank range with limit",
+			options:   GetListingsOpts{Symbol: "degods", MinRank: 101, MaxRank: 200, Limit: 30},
+			wantCount: 30,
+			wantPages: 2,
+			wantLimit: []int{100, 100},
+		},
+		{
+			name:      "All with min rank",
+			options:   GetListingsOpts{Symbol: "degods", MinRank: 240, All: true},
+			wantCount: 8,
+			wantPages: 3,
+			wantLimit: []int{100, 100, 100},
+		},
+		{ // Paging stops at the page cap before the collection runs out
+			name:      "All with min rank and max pages",
+			options:   GetListingsOpts{Symbol: "degods", MinRank: 101, All: true, MaxPages: 2},
+			wantCount: 79,
+			wantPages: 2,
+			wantLimit: []int{100, 100},
+		},
+		{
+			name:      "Limit within page size",
+			options:   GetListingsOpts{Symbol: "degods", Limit: 20},
+			wantCount: 20,
+			wantPages: 1,
+			wantLimit: []int{20},
+		},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		testName := tt.name
+
+		t.Run(testName, func(t *testing.T) {
+			limits := []int{} // Page sizes requested by the client
+
+			// Mock paginated API
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limits = append(limits, limit)
+
+				// Slice the requested page
+				end := min(offset+limit, len(collection))
+				page := collection[min(offset, end):end]
+
+				fmt.Fprintf(w, "[%s]", joinJSON(page))
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			body, err := NewClient(ClientOpts{BaseURL: server.URL, RequestsPerSecond: -1}).GetListings(context.Background(), tt.options)
+
+			if err != nil { // Error check
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			// Validate merged listings
+			listings := []listingKey{}
+			if err := json.Unmarshal(body, &listings); err != nil {
+				t.Fatalf("Merged body is not a JSON array: %v", err)
+			}
+			if len(listings) != tt.wantCount {
+				t.Errorf("Got %d listings, wanted %d", len(listings), tt.wantCount)
+			}
+
+			// Check for duplicates
+			seen := map[string]bool{}
+			for _, listing := range listings {
+				if seen[listing.Token.Mint] {
+					t.Errorf("Duplicate listing %s", listing.Token.Mint)
+				}
+				seen[listing.Token.Mint] = true
+			}
+
+			// Validate requested pages
+			if len(limits) != tt.wantPages {
+				t.Errorf("Got %d requests, wanted %d", len(limits), tt.wantPages)
+			}
+			if fmt.Sprint(limits) != fmt.Sprint(tt.wantLimit) {
+				t.Errorf("Got page sizes %v, wanted %v", limits, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// Joins raw JSON objects with commas
+func joinJSON(objects []string) string {
+	res := ""
+	for i, object := range objects {
+		if i > 0 {
+			res += ","
+		}
+		res += object
+	}
+
+	return res
+}
+
+// TestClientGetListingsCancel cancels GetListings mid-run and validates that it stops early and keeps fetched pages
+func TestClientGetListingsCancel(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name      string
+		options   GetListingsOpts
+		deadline  time.Duration // Context deadline, 0 to cancel when the second request arrives
+		wantErr   error
+		wantCount int // Wanted amount of returned listings
+	}{
+		{
+			name:      "Cancelled while paging",
+			options:   GetListingsOpts{Symbol: "degods", All: true},
+			wantErr:   context.Canceled,
+			wantCount: 100,
+		},
+		{
+			name:      "Deadline on hung request",
+			options:   GetListingsOpts{Symbol: "degods"},
+			deadline:  50 * time.Millisecond,
+			wantErr:   context.DeadlineExceeded,
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.deadline > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), tt.deadline)
+			}
+			defer cancel()
+
+			requests := 0 // Requests received by the server
+
+			// Mock server, answering the first page and hanging afterwards
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests == 1 && tt.deadline == 0 {
+					page := []string{}
+					for i := 0; i < MaxPageSize; i++ {
+						page = append(page, fmt.Sprintf(`{"tokenMint":"mint%d"}`, i))
+					}
+					fmt.Fprintf(w, "[%s]", joinJSON(page))
+					return
+				}
+
+				if tt.deadline == 0 {
+					cancel() // Cancel the run
+				}
+
+				<-r.Context().Done() // Hang until the client gives up
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{BaseURL: server.URL, RequestsPerSecond: -1})
+
+			start := time.Now()
+			body, err := client.GetListings(ctx, tt.options)
+
+			// Validate error
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Got error %v, wanted %v", err, tt.wantErr)
+			}
+
+			// Cancelled requests should not be retried or wait for the client timeout
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("GetListings took %v after cancellation", elapsed)
+			}
+
+			// Validate partial result
+			listings := []listingKey{}
+			if body != nil {
+				if err := json.Unmarshal(body, &listings); err != nil {
+					t.Fatalf("Partial body is not a JSON array: %v", err)
+				}
+			}
+			if len(listings) != tt.wantCount {
+				t.Errorf("Got %d listings, wanted %d", len(listings), tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package httpfetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mantas9/listings/models"
+)
+
+// Maximum amount of listings the API returns in a single page
+const MaxPageSize = 100
+
+// Fields used to identify a listing while de-duplicating pages
+type listingKey struct {
+	TokenMint string `json:"tokenMint"` // Listing token mint
+	Token     struct {
+		Mint string `json:"mintAddress"` // Token mint address
+	} `json:"token"`
+}
+
+func (c *Client) getListingPages(ctx context.Context, opts GetListingsOpts) ([]byte, error) { // Pages through listings with offset and merges the pages into a single JSON array
+	p := pager{
+		what:     "listings",
+		limit:    opts.Limit,
+		offset:   opts.Offset,
+		maxPages: opts.MaxPages,
+		fetch: func(ctx context.Context, limit, offset int64) ([]byte, error) {
+			pageOpts := opts
+			pageOpts.Limit = limit
+			pageOpts.Offset = offset
+
+			return c.getListingsPage(ctx, pageOpts)
+		},
+		key: listingMint,
+	}
+
+	// Keep listings of the given ranks, unranked listings are dropped
+	if opts.rankFiltered() {
+		p.fullPages = true
+		p.keep = func(item json.RawMessage) (bool, error) {
+			listing := struct {
+				Rarity models.RarityJSON `json:"rarity"`
+			}{}
+			json.Unmarshal(item, &listing) // Malformed ranks count as unranked
+
+			rank := listing.Rarity.Rank()
+			return rank > 0 && rank >= opts.MinRank && (opts.MaxRank == 0 || rank <= opts.MaxRank), nil
+		}
+
+		// A single filtered page unless paging was requested
+		if !opts.All && opts.Limit == 0 {
+			p.maxPages = 1
+		}
+	}
+
+	return p.run(ctx)
+}
+
+// Pages through an endpoint with limit and offset, merging the unique items of every page into a single JSON array
+type pager struct {
+	what      string                                                         // Name of the items in errors, e.g. "listings"
+	limit     int64                                                          // Maximum amount of merged items, 0 for no limit
+	offset    int64                                                          // Offset of the first page
+	maxPages  int                                                            // Maximum amount of fetched pages, 0 for no limit
+	fullPages bool                                                           // Always request full pages, e.g. when keep drops items
+	fetch     func(ctx context.Context, limit, offset int64) ([]byte, error) // Fetches a single page
+	key       func(item json.RawMessage) (string, error)                     // Identifies items to skip duplicates, "" if unknown
+	keep      func(item json.RawMessage) (bool, error)                       // Selects the merged items (optional, default - every item)
+}
+
+// Fetches pages until the endpoint runs out or the limit is reached. If ctx is cancelled while paging,
+// the items merged so far are returned along with the context error
+func (p pager) run(ctx context.Context) ([]byte, error) {
+	merged := []json.RawMessage{} // Merged items of every page
+	seen := map[string]bool{}     // Keys of items already seen
+	offset := p.offset            // Offset of the next page
+
+	for pages := 1; ; pages++ {
+		// Size of the next page
+		pageSize := int64(MaxPageSize)
+		if !p.fullPages && p.limit > 0 && p.limit-int64(len(merged)) < pageSize {
+			pageSize = p.limit - int64(len(merged))
+		}
+
+		// Fetch page
+		data, err := p.fetch(ctx, pageSize, offset)
+
+		if err != nil && ctx.Err() != nil && len(merged) > 0 { // Cancelled, return the pages fetched so far
+			partial, marshalErr := json.Marshal(merged)
+
+			if marshalErr != nil { // Error check
+				return nil, marshalErr
+			}
+
+			return partial, err
+		}
+		if err != nil { // Error check
+			return nil, err
+		}
+
+		// Split page into separate items
+		page := []json.RawMessage{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("cannot parse %s page at offset %d: %w", p.what, offset, err)
+		}
+
+		// Merge items which were not seen in previous pages
+		added := 0 // Items which were not seen before, kept or not
+		for _, item := range page {
+			key, err := p.key(item)
+
+			if err != nil { // Error check
+				return nil, err
+			}
+
+			if key != "" && seen[key] { // Skip duplicates
+				continue
+			}
+			seen[key] = true
+			added++
+
+			// Skip filtered items
+			if p.keep != nil {
+				keep, err := p.keep(item)
+				if err != nil { // Error check
+					return nil, err
+				}
+				if !keep {
+					continue
+				}
+			}
+
+			merged = append(merged, item)
+
+			// Drop the rest of the page once the limit is reached
+			if p.limit > 0 && int64(len(merged)) >= p.limit {
+				break
+			}
+		}
+
+		// Move to the next page
+		offset += int64(len(page))
+
+		// Stop if the endpoint ran out, the limit was reached, the page had nothing new or enough pages were fetched
+		if int64(len(page)) < pageSize || (p.limit > 0 && int64(len(merged)) >= p.limit) || added == 0 || pages == p.maxPages {
+			break
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+func listingMint(listing json.RawMessage) (string, error) { // Returns the mint address of a raw listing
+	key := listingKey{}
+
+	if err := json.Unmarshal(listing, &key); err != nil { // Error check
+		return "", fmt.Errorf("cannot parse listing: %w", err)
+	}
+
+	// Prefer token data, fall back to the listing's token mint
+	if key.Token.Mint != "" {
+		return key.Token.Mint, nil
+	}
+
+	return key.TokenMint, nil
+}
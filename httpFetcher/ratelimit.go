@@ -0,0 +1,131 @@
+package httpfetcher
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default amount of requests per second sent to the API (MagicEden allows 120 requests per minute)
+const DefaultRequestsPerSecond = 2
+
+// Default amount of requests which can be sent at once before throttling kicks in
+const DefaultBurst = 2
+
+// Token bucket rate limiter shared by every request of a Client
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64          // Tokens added per second
+	burst       float64          // Maximum amount of stored tokens
+	tokens      float64          // Currently available tokens (negative if requests are queued)
+	last        time.Time        // Last time tokens were added (the end of the pause while paused)
+	pausedUntil time.Time        // No requests are let through until this time (set by Retry-After)
+	now         func() time.Time // Clock, replaceable in tests
+}
+
+// Creates a new rate limiter letting through rate requests per second
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Takes a token from the bucket and returns how long the caller must wait before sending its request
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	// Take a token, going below zero if the bucket is empty
+	l.tokens--
+
+	// Wait until the limiter is unpaused
+	wait := time.Duration(0)
+	if l.pausedUntil.After(now) {
+		wait = l.pausedUntil.Sub(now)
+	}
+
+	// Then until the token is refilled, which only starts again once the pause ends
+	if l.tokens < 0 {
+		wait += time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+
+	return wait
+}
+
+// Adds tokens for the time passed since the last refill (or since the end of a pause)
+func (l *rateLimiter) refill(now time.Time) {
+	if l.last.IsZero() {
+		l.last = now
+	}
+	if !now.After(l.last) { // Paused
+		return
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// Stops letting requests through for the given duration
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	// Only extend the current pause
+	until := now.Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+
+	// Keep at most one stored token and refill from the end of the pause, so once it ends a single request
+	// is let through and the queued requests follow at rate instead of bursting
+	if l.tokens > 1 {
+		l.tokens = 1
+	}
+	l.last = l.pausedUntil
+}
+
+// Parses the Retry-After header, given either in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+
+	if value == "" { // Missing header
+		return 0, false
+	}
+
+	// Delay in seconds
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	// HTTP date
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+
+		return date.Sub(now), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package httpfetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterReserve reserves tokens on a fake clock and validates the returned waits
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)          // Fake clock
+	limiter := newRateLimiter(2, 2) // 2 requests per second, burst of 2
+	limiter.now = func() time.Time { return now }
+
+	// Steps of the scenario, run in order
+	var steps = []struct {
+		name    string
+		advance time.Duration // Time passed before the reservation
+		pause   time.Duration // Pause applied before the reservation
+		want    time.Duration // Wanted wait
+	}{
+		{name: "First burst token", want: 0},
+		{name: "Second burst token", want: 0},
+		{name: "Empty bucket", want: 500 * time.Millisecond},
+		{name: "Queued behind previous", want: time.Second},
+		{name: "Refilled", advance: 2 * time.Second, want: 0},
+		{name: "Paused by Retry-After", pause: 3 * time.Second, want: 3 * time.Second},
+		{name: "Queued during the pause", want: 3500 * time.Millisecond},
+		{name: "Queued behind the pause", advance: time.Second, want: 3 * time.Second}, // Refilling starts once the pause ends
+		{name: "Queued at rate", want: 3500 * time.Millisecond},
+		{name: "Pause not shortened", pause: time.Second, want: 4 * time.Second},
+		{name: "Refilled after the pause", advance: 10 * time.Second, want: 0},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		if step.pause > 0 {
+			limiter.pause(step.pause)
+		}
+
+		if got := limiter.reserve(); got != step.want {
+			t.Errorf("%s: got wait %v, wanted %v", step.name, got, step.want)
+		}
+	}
+}
+
+// TestRetryAfter parses Retry-After headers in all supported formats
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Test table
+	var tests = []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "Missing", header: "", want: 0, wantOk: false},
+		{name: "Seconds", header: "5", want: 5 * time.Second, wantOk: true},
+		{name: "Negative", header: "-5", want: 0, wantOk: false},
+		{name: "HTTP date", header: "Wed, 01 Jan 2025 12:00:10 GMT", want: 10 * time.Second, wantOk: true},
+		{name: "Past HTTP date", header: "Wed, 01 Jan 2025 11:00:00 GMT", want: 0, wantOk: true},
+		{name: "Invalid", header: "soon", want: 0, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Retry-After", tt.header)
+			}
+
+			got, ok := retryAfter(header, now)
+
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Got (%v, %v), wanted (%v, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+// TestClientRateLimited runs GetListings against a server which rate limits the first requests
+func TestClientRateLimited(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name        string
+		limited     int // Amount of requests answered with 429
+		attempts    int // Client retry policy MaxAttempts
+		wantErr     bool
+		wantRequest int // Wanted amount of requests sent
+	}{
+		{name: "Recovers", limited: 2, attempts: 4, wantErr: false, wantRequest: 3},
+		{name: "Gives up", limited: 5, attempts: 3, wantErr: true, wantRequest: 3},
+		{name: "Retries disabled", limited: 1, attempts: 1, wantErr: true, wantRequest: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0 // Requests received by the server
+
+			// Mock server, rate limiting the first requests
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= tt.limited {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+
+				w.Write([]byte("[]"))
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{
+				BaseURL:           server.URL,
+				RequestsPerSecond: 100,
+				Retry:             &RetryPolicy{MaxAttempts: tt.attempts, RetryStatuses: []int{http.StatusTooManyRequests}},
+			})
+
+			_, err := client.GetListings(context.Background(), GetListingsOpts{Symbol: "degods"})
+
+			// Error handling scenarios
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error, but got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+
+			// Validate amount of requests
+			if requests != tt.wantRequest {
+				t.Errorf("Got %d requests, wanted %d", requests, tt.wantRequest)
+			}
+		})
+	}
+}
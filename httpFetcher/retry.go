@@ -0,0 +1,87 @@
+package httpfetcher
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// Policy for retrying failed requests
+type RetryPolicy struct {
+	MaxAttempts   int           // Total attempts of a request, including the first one (1 disables retries)
+	BaseBackoff   time.Duration // Delay before the first retry, doubled on every next retry
+	MaxBackoff    time.Duration // Maximum delay between retries (0 - unlimited)
+	Jitter        float64       // Fraction of the delay which is randomized (0 - none, 1 - full jitter)
+	RetryStatuses []int         // Response status codes which are retried
+}
+
+// Retry policy used when ClientOpts.Retry is not set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseBackoff: 500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.5,
+	RetryStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// Reports whether responses with the given status code are retried
+func (p RetryPolicy) retryStatus(code int) bool {
+	return slices.Contains(p.RetryStatuses, code)
+}
+
+// Returns the delay before the given retry (starting at 1), where random is a number in [0, 1)
+func (p RetryPolicy) backoff(retry int, random float64) time.Duration {
+	// Exponential delay, capped at MaxBackoff if set
+	delay := p.BaseBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	// Randomize part of the delay so concurrent clients don't retry in lockstep
+	jitter := min(max(p.Jitter, 0), 1)
+	return time.Duration(float64(delay) * (1 - jitter*random))
+}
+
+// Reports whether a request error is transient and worth retrying
+func retryableError(err error) bool {
+	// Timeouts
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// Dropped connections
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Reports whether a request with the given method can safely be sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// Returns a random number in [0, 1) for backoff jitter
+func jitterRandom() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,185 @@
+package httpfetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicyBackoff validates exponential backoff, its cap and jitter
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+	uncapped := RetryPolicy{BaseBackoff: 100 * time.Millisecond} // MaxBackoff 0 - unlimited
+
+	// Test table
+	var tests = []struct {
+		name   string
+		policy *RetryPolicy // Policy of the scenario (default - policy)
+		retry  int
+		random float64
+		want   time.Duration
+	}{
+		{name: "First retry", retry: 1, random: 0, want: 100 * time.Millisecond},
+		{name: "Second retry", retry: 2, random: 0, want: 200 * time.Millisecond},
+		{name: "Third retry", retry: 3, random: 0, want: 400 * time.Millisecond},
+		{name: "Capped", retry: 10, random: 0, want: time.Second},
+		{name: "Jitter", retry: 2, random: 0.5, want: 150 * time.Millisecond},
+		{name: "Capped with jitter", retry: 10, random: 1, want: 500 * time.Millisecond},
+		{name: "Uncapped first retry", policy: &uncapped, retry: 1, random: 0, want: 100 * time.Millisecond},
+		{name: "Uncapped fourth retry", policy: &uncapped, retry: 4, random: 0, want: 800 * time.Millisecond},
+		{name: "Uncapped tenth retry", policy: &uncapped, retry: 10, random: 0.5, want: 51200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			if tt.policy != nil {
+				p = *tt.policy
+			}
+
+			if got := p.backoff(tt.retry, tt.random); got != tt.want {
+				t.Errorf("Got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsIdempotent checks which request methods may be retried
+func TestIsIdempotent(t *testing.T) {
+	var tests = map[string]bool{
+		http.MethodGet:    true,
+		http.MethodHead:   true,
+		http.MethodPut:    true,
+		http.MethodDelete: true,
+		http.MethodPost:   false,
+		http.MethodPatch:  false,
+	}
+
+	for method, want := range tests {
+		if got := isIdempotent(method); got != want {
+			t.Errorf("%s: got %v, wanted %v", method, got, want)
+		}
+	}
+}
+
+// TestClientRetry runs requests against servers which fail N times and then succeed
+func TestClientRetry(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name        string
+		method      string
+		failures    int                         // Amount of failed responses before succeeding
+		fail        func(w http.ResponseWriter) // Failed response
+		attempts    int                         // Retry policy MaxAttempts
+		wantStatus  int                         // Wanted final status code
+		wantErr     bool
+		wantRequest int // Wanted amount of requests sent
+	}{
+		{
+			name:        "Recovers from 5xx",
+			method:      http.MethodGet,
+			failures:    2,
+			fail:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			attempts:    3,
+			wantStatus:  http.StatusOK,
+			wantRequest: 3,
+		},
+		{
+			name:        "Exhausts attempts",
+			method:      http.MethodGet,
+			failures:    5,
+			fail:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			attempts:    3,
+			wantStatus:  http.StatusServiceUnavailable,
+			wantRequest: 3,
+		},
+		{
+			name:        "Status not retried",
+			method:      http.MethodGet,
+			failures:    1,
+			fail:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			attempts:    3,
+			wantStatus:  http.StatusNotFound,
+			wantRequest: 1,
+		},
+		{
+			name:     "Recovers from dropped connection",
+			method:   http.MethodGet,
+			failures: 1,
+			fail: func(w http.ResponseWriter) {
+				// Close the connection without a response
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			},
+			attempts:    3,
+			wantStatus:  http.StatusOK,
+			wantRequest: 2,
+		},
+		{
+			name:        "Non-idempotent request not retried",
+			method:      http.MethodPost,
+			failures:    1,
+			fail:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			attempts:    3,
+			wantStatus:  http.StatusBadGateway,
+			wantRequest: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0 // Requests received by the server
+
+			// Mock server, failing the first requests
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= tt.failures {
+					tt.fail(w)
+					return
+				}
+
+				w.Write([]byte("[]"))
+			}))
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{
+				BaseURL:           server.URL,
+				RequestsPerSecond: -1,
+				Retry: &RetryPolicy{
+					MaxAttempts:   tt.attempts,
+					BaseBackoff:   time.Millisecond,
+					MaxBackoff:    5 * time.Millisecond,
+					RetryStatuses: DefaultRetryPolicy.RetryStatuses,
+				},
+			})
+
+			res, err := client.doRequest(context.Background(), tt.method, server.URL)
+
+			// Error handling scenarios
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error, but got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			// Validate final response
+			if res != nil {
+				defer res.Body.Close()
+
+				if res.StatusCode != tt.wantStatus {
+					t.Errorf("Got status %d, wanted %d", res.StatusCode, tt.wantStatus)
+				}
+			}
+
+			// Validate amount of requests
+			if requests != tt.wantRequest {
+				t.Errorf("Got %d requests, wanted %d", requests, tt.wantRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package httpfetcher
+
+import (
+	"context"
+	"fmt"
+)
+
+func GetCollectionStats(ctx context.Context, symbol string) ([]byte, error) { // Base GetCollectionStats function call, using DefaultClient
+	return DefaultClient.GetCollectionStats(ctx, symbol)
+}
+
+// Fetches the stats of a collection (floor price, listed count, average 24h price and total volume, prices in lamports)
+func (c *Client) GetCollectionStats(ctx context.Context, symbol string) ([]byte, error) {
+
+	// URL To API
+	url, err := c.formStatsURL(symbol)
+
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	return c.get(ctx, url, symbol, "")
+}
+
+func (c *Client) formStatsURL(symbol string) (string, error) { // Forms the magicEden API URL of collection stats
+	// Handle empty symbol
+	if symbol == "" {
+		return "", fmt.Errorf("cannot form URL to API: %w: "+`"`+"%v"+`"`, ErrInvalidSymbol, symbol)
+	}
+
+	return fmt.Sprintf("%s/v2/collections/%s/stats", c.baseURL, symbol), nil
+}
@@ -0,0 +1,82 @@
+package httpfetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientGetCollectionStats fetches collection stats from a mock server and validates the request path, body and errors
+func TestClientGetCollectionStats(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name    string
+		handler http.HandlerFunc
+		symbol  string
+		want    string
+		wantErr error // Wanted error, nil if none
+	}{
+		{
+			name: "successful request",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Validate request path
+				if r.URL.Path != "/v2/collections/degods/stats" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+
+				w.Write([]byte(`{"symbol":"degods","floorPrice":5388500000}`))
+			},
+			symbol: "degods",
+			want:   `{"symbol":"degods","floorPrice":5388500000}`,
+		},
+		{
+			name: "unknown collection",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			symbol:  "nope",
+			wantErr: ErrCollectionNotFound,
+		},
+		{
+			name: "empty symbol",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("Request should not be sent")
+			},
+			wantErr: ErrInvalidSymbol,
+		},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Mock HTTP server
+			server := httptest.NewServer(tt.handler)
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{
+				BaseURL:    server.URL,
+				HTTPClient: server.Client(),
+				Retry:      &RetryPolicy{MaxAttempts: 1},
+			})
+
+			body, err := client.GetCollectionStats(context.Background(), tt.symbol)
+
+			// Error handling scenarios
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Got error %v, wanted %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+
+			// Validate returned body
+			if string(body) != tt.want {
+				t.Errorf("Got %s, wanted %s", string(body), tt.want)
+			}
+		})
+	}
+}
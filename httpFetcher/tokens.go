@@ -0,0 +1,32 @@
+package httpfetcher
+
+import (
+	"context"
+	"fmt"
+)
+
+func GetToken(ctx context.Context, mint string) ([]byte, error) { // Base GetToken function call, using DefaultClient
+	return DefaultClient.GetToken(ctx, mint)
+}
+
+// Fetches the metadata of a token (name, image and attributes) by mint address
+func (c *Client) GetToken(ctx context.Context, mint string) ([]byte, error) {
+
+	// URL To API
+	url, err := c.formTokenURL(mint)
+
+	if err != nil { // Error check
+		return nil, err
+	}
+
+	return c.get(ctx, url, "", mint)
+}
+
+func (c *Client) formTokenURL(mint string) (string, error) { // Forms the magicEden API URL of token metadata
+	// Handle empty mint
+	if mint == "" {
+		return "", fmt.Errorf("cannot form URL to API: %w", ErrInvalidMint)
+	}
+
+	return fmt.Sprintf("%s/v2/tokens/%s", c.baseURL, mint), nil
+}
@@ -0,0 +1,86 @@
+package httpfetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestClientGetToken fetches token metadata from a mock server and validates the request path, body and errors
+func TestClientGetToken(t *testing.T) {
+
+	// Test table
+	var tests = []struct {
+		name    string
+		handler http.HandlerFunc
+		mint    string
+		want    string
+		wantErr error // Wanted error, nil if none
+	}{
+		{
+			name: "successful request",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Validate request path
+				if r.URL.Path != "/v2/tokens/mint1" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+
+				w.Write([]byte(`{"mintAddress":"mint1","name":"DeGod #1"}`))
+			},
+			mint: "mint1",
+			want: `{"mintAddress":"mint1","name":"DeGod #1"}`,
+		},
+		{
+			name: "unknown token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			mint:    "nope",
+			wantErr: ErrTokenNotFound,
+		},
+		{
+			name: "empty mint",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("Request should not be sent")
+			},
+			wantErr: ErrInvalidMint,
+		},
+	}
+
+	// Iterate through each scenario
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Mock HTTP server
+			server := httptest.NewServer(tt.handler)
+			defer server.Close() // Close server at the end of scope
+
+			client := NewClient(ClientOpts{
+				BaseURL:    server.URL,
+				HTTPClient: server.Client(),
+				Retry:      &RetryPolicy{MaxAttempts: 1},
+			})
+
+			body, err := client.GetToken(context.Background(), tt.mint)
+
+			// Error handling scenarios
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Got error %v, wanted %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected no error, but got %v", err)
+			}
+			if errors.Is(err, ErrCollectionNotFound) || (err != nil && strings.Contains(err.Error(), "collection")) {
+				t.Errorf("Token error mentions a collection: %v", err)
+			}
+
+			// Validate returned body
+			if string(body) != tt.want {
+				t.Errorf("Got %s, wanted %s", string(body), tt.want)
+			}
+		})
+	}
+}
@@ -1,183 +1,110 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"mantas9/listings/cli"
 	"mantas9/listings/constants"
-	"mantas9/listings/formatter"
 	httpfetcher "mantas9/listings/httpFetcher"
-	"mantas9/listings/models"
-	"mantas9/listings/writer"
 	"os"
-	"strconv"
-	"sync"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// Dependencies shared by every subcommand
+type app struct {
+	cfg    cli.Config          // Parsed command line parameters
+	client *httpfetcher.Client // API client shared by every fetch
+	logger *slog.Logger        // Logger writing to stderr
+}
+
+// Subcommand implementations, returning the exit code
+var commands = map[string]func(a *app, ctx context.Context) int{
+	"fetch":    (*app).runFetch,
+	"stats":    (*app).runStats,
+	"activity": (*app).runActivity,
+	"watch":    (*app).runWatch,
+	"diff":     (*app).runDiff,
+	"serve":    (*app).runServe,
+}
+
 func main() {
 	// Get arguments (exclude program call)
 	args := os.Args[1:]
 
-	// Handle parameters
-	params := httpfetcher.GetListingsOpts{}
-	argsToDrop := 0     // Counter for how many arguments to drop from args list after parsing parameters
-	valueFlag := false  // Flag to parse next value as a parameter argument
-	exportJSON := false // Flag to export to JSON instead of CSV
-
 	// If no arguments were passed, print Help message and exit
 	if len(args) <= 0 {
-		constants.HelpMessage()
+		cli.Usage(os.Stdout, "")
+		os.Exit(constants.ExitSuccess)
 	}
 
-	// iterate through each argument and parse its value
-	for i, arg := range args {
-
-		// If help was specified, print Help message
-		if arg == "--help" || arg == "-h" {
-			constants.HelpMessage()
-		}
-
-		if valueFlag { // Skip if value flag and reset
-			valueFlag = false
-			continue
-		}
-
-		if arg == "--limit" && i+1 < len(args) { // Limit param
-			// Set value flag
-			valueFlag = true
-
-			// Get limit value
-			limit, err := strconv.ParseInt(args[i+1], 10, 64)
-
-			if err != nil { // Error check
-				panic(err)
-			}
-
-			// Set parameter
-			params.Limit = limit
-
-			// Add to drop value
-			argsToDrop += 2 // param name and value
-		} else if arg == "--min-price" && i+1 < len(args) { // Minprice param
-			// Set value flag
-			valueFlag = true
-
-			// Get minPrice value
-			minPrice, err := strconv.ParseFloat(args[i+1], 64)
-
-			if err != nil { // Error check
-				panic(err)
-			}
-
-			// Set parameter
-			params.MinPrice = minPrice
-
-			// Add to drop value
-			argsToDrop += 2 // param name and value
-		} else if arg == "--max-price" && i+1 < len(args) { // Maxprice param
-			// Set value flag
-			valueFlag = true
-
-			// Get minPrice value
-			maxPrice, err := strconv.ParseFloat(args[i+1], 64)
-
-			if err != nil { // Error check
-				panic(err)
-			}
-
-			// Set parameter
-			params.MaxPrice = maxPrice
-
-			// Add to drop value
-			argsToDrop += 2 // param name and value
-		} else if arg == "--desc" { // Descending order
-			params.Desc = true // Set descending order
-
-			argsToDrop++ // Add one parameter to drop
-		} else if arg == "--json" {
-			exportJSON = true // Flag export JSON to true
+	// Parse subcommand, parameters and arguments
+	cfg, err := cli.Parse(args, os.Getenv)
 
-			argsToDrop++ // +1 parameter to drop
-		} else if (len(arg) >= 2 && arg[:2] == "--") || arg[0] == '-' { // If invalid parameter, print help message
-			constants.HelpMessage()
-		} else { // If not a parameter and no valueflag, break loop
-			break
-		}
+	if errors.Is(err, cli.ErrHelp) { // If help was specified, print Help message
+		cli.Usage(os.Stdout, cfg.Command)
+		os.Exit(constants.ExitSuccess)
 	}
-
-	// Drop parameter arguments
-	args = args[argsToDrop:]
-
-	var wg sync.WaitGroup             // Waitgroup to prevent code from exiting prematurely
-	ch := make(chan []models.Listing) // Channel for concurrent data fetching
-
-	// Start parsing NFT data
-	for _, arg := range args {
-		wg.Add(1)
-
-		go func(symbol string) {
-			defer wg.Done()
-
-			params.Symbol = symbol // Set collection symbol in params
-
-			// Call getListings
-			listings, err := getListings(params)
-
-			// Error check
-			if err != nil {
-				fmt.Printf("Error in fetching stats:\n%s", err)
-				os.Exit(1)
-			}
-
-			if len(listings) <= 0 { // Warn user about no matches for his collection
-				fmt.Printf(`There are no matching Listings for the collection "%v" on the MagicEden Marketplace according to your parameters.`+"\nThis collection will be skipped.\n\n", symbol) // Warn user
-			}
-
-			// Push to channel
-			ch <- listings
-
-		}(arg)
-
+	if err != nil { // Invalid parameters
+		fmt.Fprintf(os.Stderr, "Error: %v\n\nRun \"./listings --help\" for usage.\n", err)
+		os.Exit(constants.ExitUsage)
 	}
 
-	// Wait for all tasks to finish
+	// Cancel the run on Ctrl-C/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	go func() {
-		wg.Wait()
-		close(ch) // Close channel
+		<-ctx.Done()
+		stop() // Restore default signal handling, so a second Ctrl-C exits immediately
 	}()
 
-	var allListings []models.Listing // Combined list of all fetched listings
+	// Run subcommand
+	logger := newLogger(cfg.Global)
+	a := &app{cfg: cfg, client: newClient(cfg.Global, logger), logger: logger}
 
-	// Iterate through channel
-	for listing := range ch {
-		allListings = append(allListings, listing...) // Append all listings data to main list
-	}
-
-	// Export everything in specified format
-	if exportJSON { // JSON
-		if err := writer.WriteJSON(allListings, "listings.json"); err != nil {
-			panic(err)
-		}
-	} else if err := writer.WriteCSV(allListings, "listings.csv"); err != nil { // Else, CSV
-		panic(err)
-	}
+	code := commands[cfg.Command](a, ctx)
 
+	stop()
+	os.Exit(code)
 }
 
-func getListings(options httpfetcher.GetListingsOpts) ([]models.Listing, error) {
-	// Fetch HTTP data
-	data, err := httpfetcher.GetListings(options)
-
-	// Error check
-	if err != nil {
-		return []models.Listing{}, err
+// Creates the stderr logger at the configured level
+func newLogger(global cli.Global) *slog.Logger {
+	// Log level (throttling decisions are logged at debug level)
+	level := slog.LevelWarn
+	if err := level.UnmarshalText([]byte(strings.ToLower(global.LogLevel))); err != nil {
+		level = slog.LevelWarn
+	}
+	if global.Verbose {
+		level = slog.LevelDebug
 	}
 
-	// Unmarshal JSON data
-	res, err := formatter.UnmarshalJSON(data)
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// Creates the API client from the shared options
+func newClient(global cli.Global, logger *slog.Logger) *httpfetcher.Client {
+	// Retry policy
+	retry := httpfetcher.DefaultRetryPolicy
+	retry.MaxAttempts = global.Retries + 1 // Retries after the first attempt
+
+	return httpfetcher.NewClient(httpfetcher.ClientOpts{
+		BaseURL:           global.BaseURL,
+		RequestsPerSecond: global.RPS,
+		Retry:             &retry,
+		Logger:            logger,
+	})
+}
 
-	// Error check
-	if err != nil {
-		return []models.Listing{}, err
+// Returns a context cancelled after the --timeout deadline, or ctx itself if no timeout was given
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
 
-	return res, nil
+	return context.WithTimeout(ctx, timeout)
 }
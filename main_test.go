@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mantas9/listings/cli"
+	"mantas9/listings/constants"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"mantas9/listings/orchestrator"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Mock API serving a single listing for every collection, except collections starting with "bad" which are not found
+func newTestAPI(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := strings.Split(r.URL.Path, "/")[3] // /v2/collections/{symbol}/listings
+		if strings.HasPrefix(symbol, "bad") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `[{"tokenMint":"%s1","seller":"seller1","price":1.5,"token":{"mintAddress":"%s1","collection":"%s"}}]`, symbol, symbol, symbol)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Creates an app running a subcommand with command line parameters, fetching from the given API without retries or rate limit
+func newTestApp(t *testing.T, baseURL, command string, args ...string) *app {
+	cfg, err := cli.Parse(append([]string{command, "--base-url", baseURL, "--retries", "0", "--rps", "-1"}, args...), func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return &app{cfg: cfg, client: newClient(cfg.Global, logger), logger: logger}
+}
+
+// TestRunFetchOutput fetches into an existing file and checks it is only replaced if a collection was fetched
+func TestRunFetchOutput(t *testing.T) {
+	server := newTestAPI(t)
+
+	// Test table
+	var tests = []struct {
+		name     string
+		file     string
+		symbols  []string
+		wantCode int
+		wantKept bool // The existing file is left untouched
+	}{
+		{
+			name:     "Streaming, every collection failed",
+			file:     "keep.ndjson",
+			symbols:  []string{"bad"},
+			wantCode: constants.ExitFailure,
+			wantKept: true,
+		},
+		{
+			name:     "Whole export, every collection failed",
+			file:     "keep.csv",
+			symbols:  []string{"bad"},
+			wantCode: constants.ExitFailure,
+			wantKept: true,
+		},
+		{
+			name:     "Streaming, partial",
+			file:     "keep.ndjson",
+			symbols:  []string{"bad", "degods"},
+			wantCode: constants.ExitPartial,
+			wantKept: false,
+		},
+		{
+			name:     "Whole export, partial",
+			file:     "keep.csv",
+			symbols:  []string{"bad", "degods"},
+			wantCode: constants.ExitPartial,
+			wantKept: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte("previous export\n"), 0o644); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			a := newTestApp(t, server.URL, "fetch", append([]string{"-o", path}, tt.symbols...)...)
+
+			if code := a.runFetch(context.Background()); code != tt.wantCode {
+				t.Errorf("Got exit code %d, wanted %d", code, tt.wantCode)
+			}
+
+			// Validate output file
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if kept := string(data) == "previous export\n"; kept != tt.wantKept {
+				t.Errorf("Got file %q, wanted kept %v", data, tt.wantKept)
+			}
+			if !tt.wantKept && !strings.Contains(string(data), "degods1") {
+				t.Errorf("Got file %q, wanted the fetched listing", data)
+			}
+		})
+	}
+}
+
+// TestExitCode combines fetch results and validates the exit code of the run
+func TestExitCode(t *testing.T) {
+	listings := []models.Listing{{Collection: "degods", Mint: "mint1"}, {Collection: "degods", Mint: "mint2"}}
+
+	// Test table
+	var tests = []struct {
+		name         string
+		results      []orchestrator.Result
+		wantListings int // Wanted amount of combined listings
+		wantFailed   int // Wanted amount of failed results
+		want         int
+	}{
+		{
+			name:         "All succeeded",
+			results:      []orchestrator.Result{{Symbol: "degods", Listings: listings}, {Symbol: "y00ts"}},
+			wantListings: 2,
+			wantFailed:   0,
+			want:         constants.ExitSuccess,
+		},
+		{
+			name:         "Partial",
+			results:      []orchestrator.Result{{Symbol: "degods", Listings: listings}, {Symbol: "degodz", Err: httpfetcher.ErrCollectionNotFound}},
+			wantListings: 2,
+			wantFailed:   1,
+			want:         constants.ExitPartial,
+		},
+		{
+			name:         "All failed with nothing exported",
+			results:      []orchestrator.Result{{Symbol: "degodz", Err: httpfetcher.ErrCollectionNotFound}, {Symbol: "y00tz", Err: httpfetcher.ErrCollectionNotFound}},
+			wantListings: 0,
+			wantFailed:   2,
+			want:         constants.ExitFailure,
+		},
+		{ // Interrupted while paging, the listings fetched so far are exported
+			name:         "All failed with partial listings exported",
+			results:      []orchestrator.Result{{Symbol: "degods", Listings: listings, Err: context.Canceled}, {Symbol: "y00ts", Err: context.Canceled}},
+			wantListings: 2,
+			wantFailed:   2,
+			want:         constants.ExitPartial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allListings, failed := collectResults(tt.results)
+
+			if len(allListings) != tt.wantListings || len(failed) != tt.wantFailed {
+				t.Errorf("Got %d listings and %d failures, wanted %d and %d", len(allListings), len(failed), tt.wantListings, tt.wantFailed)
+			}
+
+			if got := exitCode(failed, len(tt.results), len(allListings)); got != tt.want {
+				t.Errorf("Got exit code %d, wanted %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFailureReason maps fetch errors, wrapped like the API client wraps them, to their explanations
+func TestFailureReason(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Fail-fast", err: fmt.Errorf("fetching: %w", orchestrator.ErrFailFast), want: "cancelled (fail-fast)"},
+		{name: "Collection not found", err: &httpfetcher.APIError{StatusCode: http.StatusNotFound, Symbol: "degodz"}, want: "collection not found"},
+		{name: "Rate limited", err: &httpfetcher.APIError{StatusCode: http.StatusTooManyRequests, Symbol: "degods"}, want: "rate limited by API after every retry (try again later or lower --rps)"},
+		{name: "Invalid symbol", err: fmt.Errorf("cannot form URL to API: %w", httpfetcher.ErrInvalidSymbol), want: "invalid collection symbol"},
+		{name: "Cancelled", err: context.Canceled, want: "cancelled"},
+		{name: "Timed out", err: fmt.Errorf("fetching: %w", context.DeadlineExceeded), want: "timed out (--timeout)"},
+		{name: "Other", err: errors.New("unexpected EOF"), want: "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureReason(tt.err); got != tt.want {
+				t.Errorf("Got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // ========= Nested Structs (for JSON unmarshaling) ===========
 // Structure of the TokenJSON field in an NFT listing
 type TokenJSON struct {
@@ -7,11 +12,69 @@ type TokenJSON struct {
 	Collection string `json:"collection"`  // Collection name/symbol
 }
 
+// Structure of the extra field in an NFT listing
+type ExtraJSON struct {
+	Img string `json:"img"` // NFT image URL
+}
+
+// Structure of the priceInfo field in an NFT listing
+type PriceInfoJSON struct {
+	SolPrice SolPriceJSON `json:"solPrice"` // Price in SOL
+}
+
+// Structure of an on-chain token amount
+type SolPriceJSON struct {
+	RawAmount json.Number `json:"rawAmount"` // Amount in the smallest unit (lamports), sent as a string
+	Address   string      `json:"address"`   // Token mint of the currency
+	Decimals  int         `json:"decimals"`  // Decimals of the currency
+}
+
+// Structure of the rarity field in an NFT listing, with a rank per provider
+type RarityJSON struct {
+	MERarity  RankJSON `json:"merarity"`  // Magic Eden rank
+	MEInstant RankJSON `json:"meInstant"` // Magic Eden rank of collections without merarity
+	Moonrank  RankJSON `json:"moonrank"`  // Moonrank rank
+	HowRare   RankJSON `json:"howrare"`   // HowRare.is rank
+}
+
+// Structure of a rarity provider's rank, 1 being the rarest
+type RankJSON struct {
+	Rank int64 `json:"rank"`
+}
+
+// Returns the Magic Eden rank, 0 if unranked
+func (r RarityJSON) MERank() int64 {
+	if r.MERarity.Rank > 0 {
+		return r.MERarity.Rank
+	}
+
+	return r.MEInstant.Rank
+}
+
+// Returns the rank used for filtering and sorting: the Magic Eden rank, else Moonrank, else HowRare, 0 if unranked
+func (r RarityJSON) Rank() int64 {
+	for _, rank := range []int64{r.MERank(), r.Moonrank.Rank, r.HowRare.Rank} {
+		if rank > 0 {
+			return rank
+		}
+	}
+
+	return 0
+}
+
 // Structure of a single NFT listing
 type ListingJSON struct {
-	Seller    string    `json:"seller"` // Seller address
-	Price     float64   `json:"price"`  // NFT price in SOL
-	TokenData TokenJSON `json:"token"`  // Token data
+	PDAAddress    string          `json:"pdaAddress"`    // Listing account address
+	AuctionHouse  string          `json:"auctionHouse"`  // Auction house of the listing
+	Seller        string          `json:"seller"`        // Seller address
+	TokenSize     int64           `json:"tokenSize"`     // Amount of listed tokens
+	Price         float64         `json:"price"`         // NFT price in SOL
+	PriceInfo     PriceInfoJSON   `json:"priceInfo"`     // NFT price in lamports
+	Rarity        json.RawMessage `json:"rarity"`        // Rarity ranks by provider
+	Extra         ExtraJSON       `json:"extra"`         // Extra data, e.g. the image URL
+	Expiry        int64           `json:"expiry"`        // Unix time the listing expires at, -1 if never
+	ListingSource string          `json:"listingSource"` // Marketplace program of the listing
+	TokenData     TokenJSON       `json:"token"`         // Token data
 }
 
 // ========= Flat Struct for CSV data ==========
@@ -20,4 +83,103 @@ type Listing struct {
 	Seller     string  `csv:"seller" json:"seller"`
 	Price      float64 `csv:"price" json:"price"`
 	Mint       string  `csv:"mintAddress" json:"mintAddress"`
+
+	// Optional fields of the API response, only exported when selected with --fields (see formatter.ListingFields)
+	PDAAddress    string          `csv:"-" json:"pdaAddress,omitempty"`
+	AuctionHouse  string          `csv:"-" json:"auctionHouse,omitempty"`
+	TokenSize     int64           `csv:"-" json:"tokenSize,omitempty"`
+	Expiry        int64           `csv:"-" json:"expiry,omitempty"`
+	Rarity        json.RawMessage `csv:"-" json:"rarity,omitempty"`
+	ListingSource string          `csv:"-" json:"listingSource,omitempty"`
+	Img           string          `csv:"-" json:"img,omitempty"`
+	PriceLamports int64           `csv:"-" json:"priceLamports,omitempty"`
+	Rank          int64           `csv:"-" json:"rank,omitempty"`        // Rank used by --min-rank, --max-rank and --sort (see RarityJSON.Rank)
+	MERank        int64           `csv:"-" json:"meRank,omitempty"`      // Magic Eden rank
+	MoonRank      int64           `csv:"-" json:"moonRank,omitempty"`    // Moonrank rank
+	HowRareRank   int64           `csv:"-" json:"howRareRank,omitempty"` // HowRare.is rank
+
+	// Token metadata, only set by --enrich (CSV exports flatten it into extra columns)
+	Name       string      `csv:"-" json:"name,omitempty"`       // Token name
+	Image      string      `csv:"-" json:"image,omitempty"`      // Token image URL
+	Attributes []Attribute `csv:"-" json:"attributes,omitempty"` // Token traits
+
+	Raw json.RawMessage `csv:"-" json:"-"` // Original API object, exported by --raw
+}
+
+// Trait of a token
+type Attribute struct {
+	TraitType string `json:"trait_type"` // Trait name, e.g. background
+	Value     string `json:"value"`      // Trait value
+}
+
+// ========= Token metadata ==========
+// Structure of token metadata returned by the API
+type TokenMetadataJSON struct {
+	Mint       string          `json:"mintAddress"` // NFT mint address
+	Name       string          `json:"name"`        // Token name
+	Image      string          `json:"image"`       // Token image URL
+	Attributes []AttributeJSON `json:"attributes"`  // Token traits
+}
+
+// Structure of a token trait, whose value may be a string or a number
+type AttributeJSON struct {
+	TraitType string `json:"trait_type"`
+	Value     any    `json:"value"`
+}
+
+// Token metadata merged into listings
+type TokenMetadata struct {
+	Mint       string
+	Name       string
+	Image      string
+	Attributes []Attribute
+}
+
+// Lamports in one SOL, the unit of prices returned by collection stats
+const LamportsPerSOL = 1_000_000_000
+
+// ========= Collection stats ==========
+// Structure of collection stats returned by the API (prices in lamports)
+type CollectionStatsJSON struct {
+	Symbol       string  `json:"symbol"`       // Collection symbol
+	FloorPrice   float64 `json:"floorPrice"`   // Lowest listed price
+	ListedCount  int     `json:"listedCount"`  // Amount of listed NFTs
+	AvgPrice24hr float64 `json:"avgPrice24hr"` // Average sale price in the last 24 hours
+	VolumeAll    float64 `json:"volumeAll"`    // Total traded volume
+}
+
+// Flat struct of collection stats (prices in SOL)
+type CollectionStats struct {
+	Collection  string  `csv:"collection" json:"collection"`
+	FloorPrice  float64 `csv:"floorPrice" json:"floorPrice"`
+	ListedCount int     `csv:"listedCount" json:"listedCount"`
+	AvgPrice24h float64 `csv:"avgPrice24h" json:"avgPrice24h"`
+	TotalVolume float64 `csv:"totalVolume" json:"totalVolume"`
+}
+
+// ========= Collection activities ==========
+// Structure of a single collection activity returned by the API
+type ActivityJSON struct {
+	Signature  string  `json:"signature"`  // Transaction signature
+	Type       string  `json:"type"`       // Activity type, e.g. list, delist, buyNow or bid
+	Source     string  `json:"source"`     // Marketplace program
+	TokenMint  string  `json:"tokenMint"`  // NFT mint address
+	Collection string  `json:"collection"` // Collection symbol
+	BlockTime  int64   `json:"blockTime"`  // Unix time of the transaction
+	Buyer      string  `json:"buyer"`      // Buyer address (bids and sales)
+	Seller     string  `json:"seller"`     // Seller address
+	Price      float64 `json:"price"`      // Price in SOL
+}
+
+// Flat struct of a collection activity
+type Activity struct {
+	Collection string    `csv:"collection" json:"collection"`
+	Type       string    `csv:"type" json:"type"`
+	Time       time.Time `csv:"time" json:"time"`
+	Price      float64   `csv:"price" json:"price"`
+	Seller     string    `csv:"seller" json:"seller"`
+	Buyer      string    `csv:"buyer" json:"buyer"`
+	Mint       string    `csv:"mintAddress" json:"mintAddress"`
+	Signature  string    `csv:"signature" json:"signature"`
+	Source     string    `csv:"source" json:"source"`
 }
@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"sync"
+)
+
+// Default amount of collections fetched at the same time
+const DefaultConcurrency = 4
+
+// Error of jobs which were cancelled because another job failed in fail-fast mode
+var ErrFailFast = errors.New("cancelled after another collection failed (fail-fast)")
+
+// Fetches the listings of a single collection
+type FetchFunc func(ctx context.Context, opts httpfetcher.GetListingsOpts) ([]models.Listing, error)
+
+// Outcome of fetching a single collection
+type Result struct {
+	Symbol   string           // Collection symbol
+	Listings []models.Listing // Fetched listings (may be partial if Err is set)
+	Err      error            // Fetch error, nil on success
+}
+
+// Outcome of a RunSymbols job
+type SymbolResult[T any] struct {
+	Symbol string // Collection symbol
+	Value  T      // Fetched data (may be partial or zero if Err is set)
+	Err    error  // Fetch error, nil on success
+}
+
+// Run call parameters
+type RunOpts struct {
+	Concurrency int  // Maximum amount of collections fetched at the same time (default - DefaultConcurrency)
+	FailFast    bool // Cancel remaining jobs as soon as one job fails
+
+	// Called with each result of Run as soon as its job finishes, in completion order. Calls never overlap
+	OnResult func(Result)
+}
+
+// Creates one job per collection symbol, each with its own copy of the base options
+func Jobs(base httpfetcher.GetListingsOpts, symbols []string) []httpfetcher.GetListingsOpts {
+	jobs := make([]httpfetcher.GetListingsOpts, len(symbols))
+
+	for i, symbol := range symbols {
+		jobs[i] = base // Copy options
+		jobs[i].Symbol = symbol
+	}
+
+	return jobs
+}
+
+// Runs every job through a worker pool and returns their results in the same order as the jobs
+func Run(ctx context.Context, jobs []httpfetcher.GetListingsOpts, fetch FetchFunc, opts RunOpts) []Result {
+	results := make([]Result, len(jobs)) // Results, indexed like jobs
+	var resultMu sync.Mutex              // Serializes OnResult calls
+
+	runPool(ctx, len(jobs), opts, func(runCtx context.Context, i int) error {
+		results[i] = Result{Symbol: jobs[i].Symbol}
+		results[i].Listings, results[i].Err = runJob(ctx, runCtx, func(ctx context.Context) ([]models.Listing, error) {
+			return fetch(ctx, jobs[i])
+		})
+
+		// Hand the result over as soon as it is ready
+		if opts.OnResult != nil {
+			resultMu.Lock()
+			opts.OnResult(results[i])
+			resultMu.Unlock()
+		}
+
+		return results[i].Err
+	})
+
+	return results
+}
+
+// Runs a fetch per collection symbol (e.g. stats or activities) through a worker pool and returns their results in the same order as the symbols.
+// opts.OnResult is not called
+func RunSymbols[T any](ctx context.Context, symbols []string, fetch func(ctx context.Context, symbol string) (T, error), opts RunOpts) []SymbolResult[T] {
+	results := make([]SymbolResult[T], len(symbols)) // Results, indexed like symbols
+
+	runPool(ctx, len(symbols), opts, func(runCtx context.Context, i int) error {
+		results[i] = SymbolResult[T]{Symbol: symbols[i]}
+		results[i].Value, results[i].Err = runJob(ctx, runCtx, func(ctx context.Context) (T, error) {
+			return fetch(ctx, symbols[i])
+		})
+
+		return results[i].Err
+	})
+
+	return results
+}
+
+// Calls run with the index of every job (0 to n-1) through a bounded worker pool, waiting for all of them.
+// In fail-fast mode, the first error returned by run cancels the context given to the remaining jobs
+func runPool(ctx context.Context, n int, opts RunOpts, run func(runCtx context.Context, i int) error) {
+	// Worker pool size
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	concurrency = min(concurrency, n)
+
+	// Context cancelled on the first failure in fail-fast mode
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	queue := make(chan int) // Indexes of jobs to run
+
+	var wg sync.WaitGroup // Waitgroup of workers
+
+	// Start workers
+	for range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				// Stop every other job on failure
+				if err := run(runCtx, i); err != nil && opts.FailFast {
+					cancel(ErrFailFast)
+				}
+			}
+		}()
+	}
+
+	// Queue every job in order
+	for i := range n {
+		queue <- i
+	}
+	close(queue)
+
+	// Wait for all workers to finish
+	wg.Wait()
+}
+
+// Runs a single job, marking jobs cancelled by fail-fast
+func runJob[T any](ctx, runCtx context.Context, fetch func(ctx context.Context) (T, error)) (T, error) {
+	// Skip jobs which were queued after the run was cancelled
+	if runCtx.Err() != nil {
+		var zero T
+		return zero, cancelErr(ctx, runCtx, runCtx.Err())
+	}
+
+	res, err := fetch(runCtx)
+
+	// Tell fail-fast cancellation apart from the caller's cancellation
+	if err != nil && runCtx.Err() != nil && errors.Is(err, runCtx.Err()) {
+		err = cancelErr(ctx, runCtx, err)
+	}
+
+	return res, err
+}
+
+// Wraps the error of a cancelled job with ErrFailFast if the run was cancelled by fail-fast
+func cancelErr(ctx, runCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(context.Cause(runCtx), ErrFailFast) {
+		return fmt.Errorf("%w: %w", ErrFailFast, err)
+	}
+
+	return err
+}
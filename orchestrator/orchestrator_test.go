@@ -0,0 +1,259 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestJobs checks that every job gets its own copy of the options
+func TestJobs(t *testing.T) {
+	base := httpfetcher.GetListingsOpts{Limit: 5, Desc: true}
+
+	jobs := Jobs(base, []string{"degods", "y00ts"})
+
+	// Validate symbols and copied options
+	for i, want := range []string{"degods", "y00ts"} {
+		if jobs[i].Symbol != want || jobs[i].Limit != 5 || !jobs[i].Desc {
+			t.Errorf("Job %d: got %+v", i, jobs[i])
+		}
+	}
+
+	// Base options must stay untouched
+	if base.Symbol != "" {
+		t.Errorf("Base options were modified: %+v", base)
+	}
+}
+
+// TestRunStress runs many jobs with random delays and validates isolation, ordering and the concurrency bound (run with -race)
+func TestRunStress(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name        string
+		jobs        int
+		concurrency int
+	}{
+		{name: "Sequential", jobs: 20, concurrency: 1},
+		{name: "Bounded", jobs: 200, concurrency: 8},
+		{name: "More workers than jobs", jobs: 3, concurrency: 16},
+		{name: "Default concurrency", jobs: 50, concurrency: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Collection symbols
+			symbols := []string{}
+			for i := range tt.jobs {
+				symbols = append(symbols, fmt.Sprintf("collection%d", i))
+			}
+
+			var running, peak atomic.Int64 // Currently running and peak amount of fetches
+
+			// Fetch returning a listing of the requested collection after a random delay
+			fetch := func(ctx context.Context, opts httpfetcher.GetListingsOpts) ([]models.Listing, error) {
+				now := running.Add(1)
+				defer running.Add(-1)
+
+				// Record peak concurrency
+				for old := peak.Load(); now > old && !peak.CompareAndSwap(old, now); old = peak.Load() {
+				}
+
+				time.Sleep(time.Duration(rand.IntN(500)) * time.Microsecond)
+
+				return []models.Listing{{Collection: opts.Symbol}}, nil
+			}
+
+			results := Run(context.Background(), Jobs(httpfetcher.GetListingsOpts{}, symbols), fetch, RunOpts{Concurrency: tt.concurrency})
+
+			// Validate order and isolation
+			if len(results) != len(symbols) {
+				t.Fatalf("Got %d results, wanted %d", len(results), len(symbols))
+			}
+			for i, res := range results {
+				if res.Symbol != symbols[i] || res.Err != nil {
+					t.Errorf("Result %d: got %+v, wanted collection %s", i, res, symbols[i])
+				}
+				if len(res.Listings) != 1 || res.Listings[0].Collection != symbols[i] {
+					t.Errorf("Result %d: fetched wrong collection %+v", i, res.Listings)
+				}
+			}
+
+			// Validate concurrency bound
+			limit := int64(tt.concurrency)
+			if limit == 0 {
+				limit = DefaultConcurrency
+			}
+			if peak.Load() > limit {
+				t.Errorf("Peak concurrency %d exceeds %d", peak.Load(), limit)
+			}
+		})
+	}
+}
+
+// TestRunFailures validates per-job errors with and without fail-fast
+func TestRunFailures(t *testing.T) {
+	errBad := errors.New("bad collection")
+	symbols := []string{"good1", "bad", "good2", "good3", "good4"}
+
+	// Fetch failing the "bad" collection and waiting for cancellation on the others after it
+	fetch := func(ctx context.Context, opts httpfetcher.GetListingsOpts) ([]models.Listing, error) {
+		switch opts.Symbol {
+		case "good1":
+			return []models.Listing{{Collection: opts.Symbol}}, nil
+		case "bad":
+			return nil, errBad
+		}
+
+		// Slow collections, which can be cancelled
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return []models.Listing{{Collection: opts.Symbol}}, nil
+		}
+	}
+
+	t.Run("Keep going", func(t *testing.T) {
+		results := Run(context.Background(), Jobs(httpfetcher.GetListingsOpts{}, symbols), fetch, RunOpts{Concurrency: 2})
+
+		for i, res := range results {
+			if res.Symbol == "bad" && !errors.Is(res.Err, errBad) {
+				t.Errorf("Result %d: got error %v, wanted %v", i, res.Err, errBad)
+			}
+			if res.Symbol != "bad" && res.Err != nil {
+				t.Errorf("Result %d: unexpected error %v", i, res.Err)
+			}
+		}
+	})
+
+	t.Run("Fail fast", func(t *testing.T) {
+		results := Run(context.Background(), Jobs(httpfetcher.GetListingsOpts{}, symbols), fetch, RunOpts{Concurrency: 2, FailFast: true})
+
+		// The first collection finished before the failure
+		if results[0].Err != nil {
+			t.Errorf("Result 0: unexpected error %v", results[0].Err)
+		}
+		if !errors.Is(results[1].Err, errBad) {
+			t.Errorf("Result 1: got error %v, wanted %v", results[1].Err, errBad)
+		}
+
+		// Every collection after the failure was cancelled
+		for i, res := range results[2:] {
+			if !errors.Is(res.Err, ErrFailFast) {
+				t.Errorf("Result %d: got error %v, wanted %v", i+2, res.Err, ErrFailFast)
+			}
+		}
+	})
+
+	t.Run("Caller cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := Run(ctx, Jobs(httpfetcher.GetListingsOpts{}, symbols), fetch, RunOpts{Concurrency: 2})
+
+		for i, res := range results {
+			if !errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, ErrFailFast) {
+				t.Errorf("Result %d: got error %v, wanted %v", i, res.Err, context.Canceled)
+			}
+		}
+	})
+}
+
+// TestRunOnResult checks that every result is handed over once, without overlapping calls, before Run returns
+func TestRunOnResult(t *testing.T) {
+	symbols := []string{}
+	for i := range 50 {
+		symbols = append(symbols, fmt.Sprintf("collection%d", i))
+	}
+
+	// Fetch failing every third collection after a random delay
+	fetch := func(ctx context.Context, opts httpfetcher.GetListingsOpts) ([]models.Listing, error) {
+		time.Sleep(time.Duration(rand.IntN(500)) * time.Microsecond)
+
+		if len(opts.Symbol)%3 == 0 {
+			return nil, errors.New("failed")
+		}
+
+		return []models.Listing{{Collection: opts.Symbol}}, nil
+	}
+
+	seen := map[string]int{} // Calls per collection, unsynchronized on purpose (run with -race)
+	var inside atomic.Int64  // Calls currently running
+
+	onResult := func(res Result) {
+		if inside.Add(1) > 1 {
+			t.Errorf("OnResult calls overlap")
+		}
+		defer inside.Add(-1)
+
+		seen[res.Symbol]++
+		time.Sleep(10 * time.Microsecond)
+	}
+
+	results := Run(context.Background(), Jobs(httpfetcher.GetListingsOpts{}, symbols), fetch, RunOpts{Concurrency: 8, OnResult: onResult})
+
+	// Every result was handed over exactly once
+	if len(seen) != len(results) {
+		t.Errorf("Got %d results handed over, wanted %d", len(seen), len(results))
+	}
+	for _, res := range results {
+		if seen[res.Symbol] != 1 {
+			t.Errorf("Result %s was handed over %d times", res.Symbol, seen[res.Symbol])
+		}
+	}
+}
+
+// TestRunSymbols checks that results keep the order of symbols and that fail-fast cancels the remaining collections
+func TestRunSymbols(t *testing.T) {
+	symbols := []string{"a", "bad", "c", "d", "e"}
+
+	// Fetch failing "bad", other collections wait for cancellation
+	fetch := func(ctx context.Context, symbol string) (models.CollectionStats, error) {
+		if symbol == "bad" {
+			return models.CollectionStats{}, errors.New("failed")
+		}
+		if symbol == "a" {
+			return models.CollectionStats{Collection: symbol, ListedCount: 1}, nil
+		}
+
+		<-ctx.Done()
+		return models.CollectionStats{}, ctx.Err()
+	}
+
+	// Test table
+	var tests = []struct {
+		name     string
+		failFast bool
+		wantErrs []bool // Failed results, indexed like symbols
+	}{
+		{name: "Fail-fast", failFast: true, wantErrs: []bool{false, true, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := RunSymbols(context.Background(), symbols, fetch, RunOpts{Concurrency: 2, FailFast: tt.failFast})
+
+			for i, res := range results {
+				if res.Symbol != symbols[i] {
+					t.Errorf("Result %d: got symbol %s, wanted %s", i, res.Symbol, symbols[i])
+				}
+				if (res.Err != nil) != tt.wantErrs[i] {
+					t.Errorf("Result %d: got error %v, wanted error %v", i, res.Err, tt.wantErrs[i])
+				}
+				if res.Err != nil && res.Symbol != "bad" && !errors.Is(res.Err, ErrFailFast) {
+					t.Errorf("Result %d: got error %v, wanted %v", i, res.Err, ErrFailFast)
+				}
+			}
+
+			if results[0].Value.ListedCount != 1 {
+				t.Errorf("Got stats %+v of the first collection, wanted its fetched stats", results[0].Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mantas9/listings/constants"
+	"mantas9/listings/formatter"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Maximum amount of pages fetched per request, so all=true and rank filters don't page through a whole collection
+const serveMaxPages = 10
+
+// Serves listings over HTTP as JSON until cancelled
+func (a *app) runServe(ctx context.Context) int {
+	server := &http.Server{
+		Addr:              a.cfg.Addr,
+		Handler:           a.serveMux(),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Shut down gracefully on Ctrl-C
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		server.Shutdown(shutdownCtx)
+	}()
+
+	a.logger.Info("serving listings", "addr", a.cfg.Addr)
+	fmt.Fprintf(os.Stderr, "Serving listings on http://%s/collections/{symbol}/listings\n", a.cfg.Addr)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error in serving listings:\n%s\n", err)
+		return constants.ExitFailure
+	}
+
+	return constants.ExitSuccess
+}
+
+// Routes the served endpoints
+func (a *app) serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /collections/{symbol}/listings", a.serveListings)
+
+	return mux
+}
+
+// Handles GET /collections/{symbol}/listings. Query parameters limit, offset, min_price, max_price,
+// min_rank, max_rank, sort_direction=desc and all=true override the command line options
+func (a *app) serveListings(w http.ResponseWriter, r *http.Request) {
+	opts := a.cfg.Listings // Copy of the command line options
+	opts.Symbol = r.PathValue("symbol")
+
+	// Query overrides
+	if err := queryListingOpts(r, &opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Bound the pages fetched for the request
+	opts.MaxPages = serveMaxPages
+
+	// Apply the global deadline to each request
+	ctx, cancel := withTimeout(r.Context(), a.cfg.Timeout)
+	defer cancel()
+
+	listings, err := getListings(ctx, a.client, opts)
+
+	// Map fetch errors to status codes
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, httpfetcher.ErrCollectionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, httpfetcher.ErrRateLimited):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusGatewayTimeout
+		}
+
+		a.logger.Warn("fetch failed", "symbol", opts.Symbol, "error", err)
+		http.Error(w, failureReason(err), status)
+		return
+	}
+
+	a.logger.Info("served listings", "symbol", opts.Symbol, "listings", len(listings))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(formatter.SelectFields(listings, nil)) // Base fields only
+}
+
+// Reads listing options from query parameters
+func queryListingOpts(r *http.Request, opts *httpfetcher.GetListingsOpts) error {
+	query := r.URL.Query()
+
+	// Integer parameters
+	for name, p := range map[string]*int64{"limit": &opts.Limit, "offset": &opts.Offset, "min_rank": &opts.MinRank, "max_rank": &opts.MaxRank} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q", name, value)
+			}
+			*p = n
+		}
+	}
+
+	// Price parameters
+	for name, p := range map[string]*float64{"min_price": &opts.MinPrice, "max_price": &opts.MaxPrice} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q", name, value)
+			}
+			*p = n
+		}
+	}
+
+	// Flags
+	if value := query.Get("sort_direction"); value != "" {
+		opts.Desc = value == "desc"
+	}
+	if value := query.Get("all"); value != "" {
+		opts.All = value == "true" || value == "1"
+	}
+
+	// Ranges
+	if opts.MaxPrice > 0 && opts.MinPrice > opts.MaxPrice {
+		return fmt.Errorf("min_price (%v) must not be greater than max_price (%v)", opts.MinPrice, opts.MaxPrice)
+	}
+	if opts.MaxRank > 0 && opts.MinRank > opts.MaxRank {
+		return fmt.Errorf("min_rank (%d) must not be greater than max_rank (%d)", opts.MinRank, opts.MaxRank)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestServeListings requests listings from the served endpoints and validates the status codes and bodies
+func TestServeListings(t *testing.T) {
+	api := newTestAPI(t)
+	mux := newTestApp(t, api.URL, "serve").serveMux()
+
+	// Test table
+	var tests = []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string // Wanted start of the body
+	}{
+		{
+			name:       "Health",
+			target:     "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "Listings",
+			target:     "/collections/degods/listings?limit=5&min_price=1&max_price=2",
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"collection":"degods","seller":"seller1","price":1.5,"mintAddress":"degods1"}]`,
+		},
+		{
+			name:       "Invalid limit",
+			target:     "/collections/degods/listings?limit=-1",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `invalid limit "-1"`,
+		},
+		{
+			name:       "Min over max price",
+			target:     "/collections/degods/listings?min_price=5&max_price=1",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "min_price (5) must not be greater than max_price (1)",
+		},
+		{
+			name:       "Min over max rank",
+			target:     "/collections/degods/listings?min_rank=10&max_rank=5",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "min_rank (10) must not be greater than max_rank (5)",
+		},
+		{
+			name:       "Unknown collection",
+			target:     "/collections/bad/listings",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "collection not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Got status %d, wanted %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.wantBody) {
+				t.Errorf("Got body %q, wanted %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestServeListingsPageCap requests listings of an endless collection and checks that paging stops at serveMaxPages
+func TestServeListingsPageCap(t *testing.T) {
+	// Mock API with full pages of ranked listings at every offset
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var offset int
+		fmt.Sscan(r.URL.Query().Get("offset"), &offset)
+
+		listings := []string{}
+		for i := offset; i < offset+100; i++ {
+			listings = append(listings, fmt.Sprintf(`{"tokenMint":"mint%d","price":1,"rarity":{"moonrank":{"rank":%d}},"token":{"mintAddress":"mint%d"}}`, i, i+1, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(listings, ","))
+	}))
+	defer api.Close()
+
+	mux := newTestApp(t, api.URL, "serve").serveMux()
+
+	// Test table
+	var tests = []struct {
+		name   string
+		target string
+	}{
+		{name: "All", target: "/collections/degods/listings?all=true"},
+		{name: "Rank filter with limit", target: "/collections/degods/listings?min_rank=100000&limit=10"},
+		{name: "All with rank filter", target: "/collections/degods/listings?max_rank=100000&all=true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("Got status %d, wanted %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if requests != serveMaxPages {
+				t.Errorf("Got %d requests, wanted %d", requests, serveMaxPages)
+			}
+		})
+	}
+}
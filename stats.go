@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mantas9/listings/constants"
+	"mantas9/listings/formatter"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"mantas9/listings/orchestrator"
+	"mantas9/listings/writer"
+	"os"
+)
+
+// Fetches the stats of every collection and exports them in the specified format
+func (a *app) runStats(ctx context.Context) int {
+	// Cancel the run after the global deadline
+	ctx, cancel := withTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	// Writer of the specified format
+	format, ok := writer.Lookup(a.cfg.Format)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", a.cfg.Format)
+		return constants.ExitFailure
+	}
+	w, err := a.newWriter(format)
+	if err != nil { // Invalid template
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return constants.ExitUsage
+	}
+	sw, ok := w.(writer.StatsWriter)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Format %q cannot export stats\n", a.cfg.Format)
+		return constants.ExitFailure
+	}
+
+	// Fetch every collection
+	results := orchestrator.RunSymbols(ctx, a.cfg.Symbols, func(ctx context.Context, symbol string) (models.CollectionStats, error) {
+		return getCollectionStats(ctx, a.client, symbol)
+	}, orchestrator.RunOpts{Concurrency: a.cfg.Concurrency, FailFast: a.cfg.FailFast})
+
+	// Stats of fetched collections in command line order
+	collections := []models.CollectionStats{}
+	var failed []orchestrator.Result // Collections which failed to fetch
+	for _, res := range results {
+		if res.Err != nil { // Record failure
+			failed = append(failed, orchestrator.Result{Symbol: res.Symbol, Err: res.Err})
+			continue
+		}
+		collections = append(collections, res.Value)
+	}
+
+	a.reportFailures(ctx, failed)
+
+	// Nothing to write if every collection failed
+	if len(failed) == len(a.cfg.Symbols) {
+		return constants.ExitFailure
+	}
+
+	// Export stats in specified format
+	out, closeOut, path, err := a.openOutput(format)
+	if err == nil {
+		err = sw.WriteStats(context.WithoutCancel(ctx), out, collections)
+
+		if closeErr := closeOut(); err == nil { // Report failed flushes
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in writing stats:\n%s\n", err)
+		return constants.ExitFailure
+	}
+
+	// Print success message
+	if a.cfg.Output != "-" {
+		fmt.Fprintf(os.Stderr, "Your selected collections' stats have been written to %s successfully.\n", path)
+	}
+
+	return exitCode(failed, len(a.cfg.Symbols), len(collections))
+}
+
+// Fetches and unmarshals the stats of a collection
+func getCollectionStats(ctx context.Context, client *httpfetcher.Client, symbol string) (models.CollectionStats, error) {
+	// Fetch HTTP data
+	data, err := client.GetCollectionStats(ctx, symbol)
+
+	if err != nil { // Error check
+		return models.CollectionStats{}, err
+	}
+
+	// Unmarshal JSON data
+	return formatter.UnmarshalStatsJSON(data)
+}
@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"mantas9/listings/models"
+	"slices"
+)
+
+// Price summary of a collection's listings
+type Summary struct {
+	Collection string  `csv:"collection" json:"collection"`
+	Count      int     `csv:"count" json:"count"`
+	Floor      float64 `csv:"floor" json:"floor"`
+	Median     float64 `csv:"median" json:"median"`
+	Mean       float64 `csv:"mean" json:"mean"`
+	Max        float64 `csv:"max" json:"max"`
+}
+
+// Summarizes listings per collection, in the order collections first appear
+func Summarize(listings []models.Listing) []Summary {
+	order := []string{}              // Collections in order of appearance
+	prices := map[string][]float64{} // Prices per collection
+
+	for _, listing := range listings {
+		if _, ok := prices[listing.Collection]; !ok {
+			order = append(order, listing.Collection)
+		}
+		prices[listing.Collection] = append(prices[listing.Collection], listing.Price)
+	}
+
+	// Summarize each collection
+	res := []Summary{}
+	for _, collection := range order {
+		res = append(res, summarize(collection, prices[collection]))
+	}
+
+	return res
+}
+
+// Summarizes the prices of a single collection
+func summarize(collection string, prices []float64) Summary {
+	sorted := slices.Clone(prices)
+	slices.Sort(sorted)
+
+	// Mean
+	total := 0.0
+	for _, price := range sorted {
+		total += price
+	}
+
+	return Summary{
+		Collection: collection,
+		Count:      len(sorted),
+		Floor:      sorted[0],
+		Median:     Median(sorted),
+		Mean:       total / float64(len(sorted)),
+		Max:        sorted[len(sorted)-1],
+	}
+}
+
+// Returns the median of sorted prices, 0 if there are none
+func Median(sorted []float64) float64 {
+	n := len(sorted)
+
+	switch {
+	case n == 0:
+		return 0
+	case n%2 == 1:
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// Price range of a histogram and the amount of prices within it
+type Bin struct {
+	From  float64 // Lower bound (inclusive)
+	To    float64 // Upper bound (exclusive, inclusive for the last bin)
+	Count int     // Amount of prices within the bounds
+}
+
+// Splits prices into bins of equal width between the lowest and highest price. All prices fall into a single bin if they are equal
+func Histogram(prices []float64, bins int) []Bin {
+	if len(prices) == 0 || bins <= 0 {
+		return []Bin{}
+	}
+
+	low, high := slices.Min(prices), slices.Max(prices)
+	if low == high {
+		return []Bin{{From: low, To: high, Count: len(prices)}}
+	}
+
+	// Bin bounds
+	width := (high - low) / float64(bins)
+	res := make([]Bin, bins)
+	for i := range res {
+		res[i].From = low + float64(i)*width
+		res[i].To = low + float64(i+1)*width
+	}
+	res[bins-1].To = high // Avoid rounding errors
+
+	// Count prices, the highest one belongs to the last bin
+	for _, price := range prices {
+		i := min(int((price-low)/width), bins-1)
+		res[i].Count++
+	}
+
+	return res
+}
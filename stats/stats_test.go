@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"mantas9/listings/models"
+	"reflect"
+	"testing"
+)
+
+// TestSummarize calls Summarize with listings of several collections and validates the summaries
+func TestSummarize(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name  string
+		input []models.Listing
+		want  []Summary
+	}{
+		{
+			name: "Multiple collections",
+			input: []models.Listing{
+				{Collection: "degods", Price: 5},
+				{Collection: "y00ts", Price: 1},
+				{Collection: "degods", Price: 3},
+				{Collection: "degods", Price: 4},
+				{Collection: "degods", Price: 8},
+			},
+			want: []Summary{
+				{Collection: "degods", Count: 4, Floor: 3, Median: 4.5, Mean: 5, Max: 8},
+				{Collection: "y00ts", Count: 1, Floor: 1, Median: 1, Mean: 1, Max: 1},
+			},
+		},
+		{
+			name:  "Odd count",
+			input: []models.Listing{{Collection: "degods", Price: 2}, {Collection: "degods", Price: 9}, {Collection: "degods", Price: 1}},
+			want:  []Summary{{Collection: "degods", Count: 3, Floor: 1, Median: 2, Mean: 4, Max: 9}},
+		},
+		{
+			name:  "Empty",
+			input: []models.Listing{},
+			want:  []Summary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHistogram calls Histogram with spread out, equal and no prices
+func TestHistogram(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name   string
+		prices []float64
+		bins   int
+		want   []Bin
+	}{
+		{
+			name:   "Spread out",
+			prices: []float64{1, 1.5, 2, 2.9, 3, 5},
+			bins:   4,
+			want:   []Bin{{From: 1, To: 2, Count: 2}, {From: 2, To: 3, Count: 2}, {From: 3, To: 4, Count: 1}, {From: 4, To: 5, Count: 1}},
+		},
+		{
+			name:   "Equal prices",
+			prices: []float64{2, 2, 2},
+			bins:   10,
+			want:   []Bin{{From: 2, To: 2, Count: 3}},
+		},
+		{
+			name:   "No prices",
+			prices: []float64{},
+			bins:   10,
+			want:   []Bin{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Histogram(tt.prices, tt.bins); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %+v, wanted %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mantas9/listings/constants"
+	"mantas9/listings/diff"
+	"mantas9/listings/models"
+	"time"
+)
+
+// Fetches listings periodically and prints new, removed and repriced listings until cancelled
+func (a *app) runWatch(ctx context.Context) int {
+	snapshot := map[string][]models.Listing{} // Last fetched listings per collection
+
+	for i := 0; a.cfg.Count == 0 || i < a.cfg.Count; i++ {
+		// Wait for the next fetch
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return constants.ExitSuccess
+			case <-time.After(a.cfg.Interval):
+			}
+		}
+
+		// Fetch every collection, with the global deadline applied to each fetch
+		fetchCtx, cancel := withTimeout(ctx, a.cfg.Timeout)
+		results := a.fetchCollections(fetchCtx, nil)
+		cancel()
+
+		// Stopped with Ctrl-C
+		if ctx.Err() != nil {
+			return constants.ExitSuccess
+		}
+
+		now := time.Now().Format(time.TimeOnly)
+
+		for _, res := range results {
+			// Keep the previous snapshot of failed collections
+			if res.Err != nil {
+				fmt.Printf("[%s] %s: fetch failed: %s\n", now, res.Symbol, failureReason(res.Err))
+				continue
+			}
+
+			prev, seen := snapshot[res.Symbol]
+			snapshot[res.Symbol] = res.Listings
+
+			// First snapshot
+			if !seen {
+				fmt.Printf("[%s] %s: watching %d listings\n", now, res.Symbol, len(res.Listings))
+				continue
+			}
+
+			printChanges(now, res.Symbol, diff.Compare(prev, res.Listings))
+		}
+	}
+
+	return constants.ExitSuccess
+}
+
+// Prints the changes of a collection, one line per listing
+func printChanges(now, symbol string, changes diff.Changes) {
+	for _, listing := range changes.Added {
+		fmt.Printf("[%s] %s: + %s listed for %v SOL by %s\n", now, symbol, listing.Mint, listing.Price, listing.Seller)
+	}
+	for _, listing := range changes.Removed {
+		fmt.Printf("[%s] %s: - %s (%v SOL) is no longer listed\n", now, symbol, listing.Mint, listing.Price)
+	}
+	for _, change := range changes.Repriced {
+		fmt.Printf("[%s] %s: ~ %s repriced from %v to %v SOL\n", now, symbol, change.Mint, change.OldPrice, change.Price)
+	}
+}
@@ -0,0 +1,129 @@
+package writer
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"mantas9/listings/formatter"
+	"mantas9/listings/models"
+	"slices"
+
+	"github.com/gocarina/gocsv"
+)
+
+func init() {
+	Register(Format{
+		Name:        "csv",
+		Extensions:  []string{".csv"},
+		Description: "Comma-separated values with a header row",
+		New:         func(opts Options) Writer { return csvWriter{fields: formatter.SelectedFields(opts.Fields)} },
+	})
+}
+
+// Writes listings as CSV
+type csvWriter struct {
+	fields []formatter.ListingField // Optional fields added as columns
+}
+
+func (c csvWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	// Selected fields and enriched listings get extra columns
+	if len(c.fields) > 0 || slices.ContainsFunc(listings, enriched) {
+		return writeExtendedCSV(w, listings, c.fields)
+	}
+
+	return gocsv.Marshal(&listings, w)
+}
+
+func (csvWriter) WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error {
+	return gocsv.Marshal(&stats, w)
+}
+
+func (csvWriter) WriteActivities(ctx context.Context, w io.Writer, activities []models.Activity) error {
+	return gocsv.Marshal(&activities, w)
+}
+
+// Columns of every listing
+var csvBaseHeader = []string{"collection", "seller", "price", "mintAddress"}
+
+// Prefix of the columns of flattened token attributes, e.g. attributes.background
+const csvAttributePrefix = "attributes."
+
+// Tells whether token metadata was merged into a listing
+func enriched(listing models.Listing) bool {
+	return listing.Name != "" || listing.Image != "" || len(listing.Attributes) > 0
+}
+
+// Returns the header of listings with the given optional fields
+func csvHeader(fields []formatter.ListingField) []string {
+	header := slices.Clone(csvBaseHeader)
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+
+	return header
+}
+
+// Returns the base columns and optional fields of a listing
+func csvRow(listing models.Listing, fields []formatter.ListingField) []string {
+	row := []string{listing.Collection, listing.Seller, formatPrice(listing.Price), listing.Mint}
+	for _, field := range fields {
+		row = append(row, field.Value(listing))
+	}
+
+	return row
+}
+
+// Writes listings with their optional fields and, if any listing is enriched, their token name, image
+// and one column per attribute, in the order trait types first appear
+func writeExtendedCSV(w io.Writer, listings []models.Listing, fields []formatter.ListingField) error {
+	withMetadata := slices.ContainsFunc(listings, enriched)
+
+	// Trait types of every listing
+	traits := []string{}
+	seen := map[string]bool{}
+	for _, listing := range listings {
+		for _, attribute := range listing.Attributes {
+			if !seen[attribute.TraitType] {
+				seen[attribute.TraitType] = true
+				traits = append(traits, attribute.TraitType)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+
+	// Header
+	header := csvHeader(fields)
+	if withMetadata {
+		header = append(header, "name", "image")
+	}
+	for _, trait := range traits {
+		header = append(header, csvAttributePrefix+trait)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	// Rows, leaving missing attributes empty
+	for _, listing := range listings {
+		values := map[string]string{}
+		for _, attribute := range listing.Attributes {
+			values[attribute.TraitType] = attribute.Value
+		}
+
+		row := csvRow(listing, fields)
+		if withMetadata {
+			row = append(row, listing.Name, listing.Image)
+		}
+		for _, trait := range traits {
+			row = append(row, values[trait])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
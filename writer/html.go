@@ -0,0 +1,156 @@
+package writer
+
+import (
+	"context"
+	_ "embed"
+	"html/template"
+	"io"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"math"
+	"strconv"
+	"time"
+)
+
+func init() {
+	Register(Format{
+		Name:        "html",
+		Extensions:  []string{".html", ".htm"},
+		Description: "Self-contained HTML report with sortable tables and price histograms",
+		New:         func(opts Options) Writer { return htmlWriter{opts: opts} },
+	})
+}
+
+// Amount of bars of price histograms
+const htmlHistogramBins = 20
+
+// Size of price histograms, in pixels
+const (
+	htmlChartWidth  = 640
+	htmlChartHeight = 160
+	htmlChartMargin = 24 // Room for axis labels
+)
+
+//go:embed templates/report.html
+var htmlReportSource string
+
+// Template of HTML reports
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"price": formatPrice,
+	"stat":  func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) },
+}).Parse(htmlReportSource))
+
+// Writes listings as an HTML report
+type htmlWriter struct {
+	opts Options
+}
+
+// Data of an HTML report
+type htmlReportData struct {
+	FetchedAt   time.Time
+	Parameters  []parameter
+	Total       int
+	Collections []htmlCollection
+}
+
+// Section of a single collection
+type htmlCollection struct {
+	ID       string // Anchor of the section
+	Summary  stats.Summary
+	Listings []models.Listing
+	Chart    htmlChart
+}
+
+// Price histogram drawn as inline SVG
+type htmlChart struct {
+	Width    int
+	Height   int
+	Left     float64 // X of the plot area's left edge
+	Right    float64 // X of the plot area's right edge
+	Baseline float64 // Y of the X axis
+	Bars     []htmlBar
+	From     float64 // Lowest price
+	To       float64 // Highest price
+	MaxCount int     // Count of the tallest bar
+}
+
+// Bar of a price histogram
+type htmlBar struct {
+	stats.Bin
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+func (h htmlWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	data := htmlReportData{
+		FetchedAt:  h.opts.fetchedAt().UTC(),
+		Parameters: h.opts.parameters(),
+		Total:      len(listings),
+	}
+
+	summaries := stats.Summarize(listings)
+	for i, group := range groupByCollection(listings) {
+		prices := []float64{}
+		for _, listing := range group {
+			prices = append(prices, listing.Price)
+		}
+
+		data.Collections = append(data.Collections, htmlCollection{
+			ID:       "collection-" + strconv.Itoa(i+1),
+			Summary:  summaries[i],
+			Listings: group,
+			Chart:    newHTMLChart(stats.Histogram(prices, htmlHistogramBins)),
+		})
+	}
+
+	return htmlReport.Execute(w, data)
+}
+
+// Lays out the bars of a histogram
+func newHTMLChart(bins []stats.Bin) htmlChart {
+	chart := htmlChart{
+		Width:    htmlChartWidth,
+		Height:   htmlChartHeight,
+		Left:     htmlChartMargin,
+		Right:    htmlChartWidth - htmlChartMargin,
+		Baseline: htmlChartHeight - htmlChartMargin,
+	}
+	if len(bins) == 0 {
+		return chart
+	}
+
+	chart.From, chart.To = bins[0].From, bins[len(bins)-1].To
+	for _, bin := range bins {
+		chart.MaxCount = max(chart.MaxCount, bin.Count)
+	}
+
+	// Plot area between the margins
+	plotWidth := float64(htmlChartWidth - 2*htmlChartMargin)
+	plotHeight := float64(htmlChartHeight - 2*htmlChartMargin)
+	barWidth := plotWidth / float64(len(bins))
+
+	for i, bin := range bins {
+		height := plotHeight * float64(bin.Count) / float64(chart.MaxCount)
+		chart.Bars = append(chart.Bars, htmlBar{
+			Bin:    bin,
+			X:      roundPixels(chart.Left + float64(i)*barWidth),
+			Y:      roundPixels(chart.Baseline - height),
+			Width:  roundPixels(barWidth - 1), // Gap between bars
+			Height: roundPixels(height),
+		})
+	}
+
+	return chart
+}
+
+// Rounds SVG coordinates to hundredths of a pixel
+func roundPixels(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Formats a price in SOL without trailing zeros
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
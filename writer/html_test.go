@@ -0,0 +1,71 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHTMLWrite writes a report and checks that it contains every section, chart and listing, with escaped values
+func TestHTMLWrite(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.5, Mint: "mint1"},
+		{Collection: "<script>alert(1)</script>", Seller: "seller2", Price: 1, Mint: "mint2"},
+		{Collection: "degods", Seller: "seller3", Price: 4.5, Mint: "mint3"},
+	}
+
+	var buf bytes.Buffer
+	hf, _ := Lookup("html")
+	opts := Options{Symbols: []string{"degods", "other"}, FetchedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := hf.New(opts).Write(context.Background(), &buf, input); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	report := buf.String()
+
+	// Test table of expected contents
+	var tests = []struct {
+		name  string
+		want  string
+		count int
+	}{
+		{name: "Fetch time", want: "3 listings fetched at 2024-01-02 03:04:05 UTC", count: 1},
+		{name: "Parameters", want: "<dt>Collections</dt><dd>degods, other</dd>", count: 1},
+		{name: "Sections", want: "<section id=", count: 2},
+		{name: "Charts", want: "<svg ", count: 2},
+		{name: "Bars", want: `<rect class="bar"`, count: htmlHistogramBins + 1},
+		{name: "Listing rows", want: `<td class="number" data-value=`, count: 3},
+		{name: "Escaped collection", want: "&lt;script&gt;alert(1)&lt;/script&gt;", count: 2},
+		{name: "Injected script", want: "<script>alert", count: 0},
+		{name: "Sorting and filtering script", want: "<script>", count: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Count(report, tt.want); got != tt.count {
+				t.Errorf("Got %d occurrences of %q, wanted %d", got, tt.want, tt.count)
+			}
+		})
+	}
+}
+
+// TestNewHTMLChart checks the layout of histogram bars
+func TestNewHTMLChart(t *testing.T) {
+	chart := newHTMLChart([]stats.Bin{{From: 1, To: 2, Count: 4}, {From: 2, To: 3, Count: 0}, {From: 3, To: 4, Count: 2}})
+
+	if chart.MaxCount != 4 || chart.From != 1 || chart.To != 4 || len(chart.Bars) != 3 {
+		t.Fatalf("Got chart %+v", chart)
+	}
+
+	// Tallest bar fills the plot area, bars are side by side
+	plotHeight := float64(htmlChartHeight - 2*htmlChartMargin)
+	if chart.Bars[0].Height != plotHeight || chart.Bars[1].Height != 0 || chart.Bars[2].Height != plotHeight/2 {
+		t.Errorf("Got bar heights %v, %v, %v", chart.Bars[0].Height, chart.Bars[1].Height, chart.Bars[2].Height)
+	}
+	if chart.Bars[0].X != htmlChartMargin || chart.Bars[2].Y+chart.Bars[2].Height != chart.Baseline {
+		t.Errorf("Got bars %+v", chart.Bars)
+	}
+}
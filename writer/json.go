@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mantas9/listings/models"
+)
+
+func init() {
+	Register(Format{
+		Name:        "json",
+		Extensions:  []string{".json"},
+		Description: "A single JSON array of listings",
+		New:         func(opts Options) Writer { return jsonWriter{raw: opts.Raw} },
+	})
+}
+
+// Writes listings as a JSON array
+type jsonWriter struct {
+	raw bool // Write original API objects
+}
+
+func (j jsonWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	// Original API objects
+	if j.raw {
+		raws := []json.RawMessage{}
+		for _, listing := range listings {
+			raws = append(raws, listing.Raw)
+		}
+
+		return writeJSONValue(w, raws)
+	}
+
+	json, err := json.Marshal(listings) // Marshal JSON
+
+	if err != nil { // Error check
+		return err
+	}
+
+	// Write output
+	_, err = w.Write(json)
+
+	return err
+}
+
+func (jsonWriter) WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error {
+	return writeJSONValue(w, stats)
+}
+
+func (jsonWriter) WriteActivities(ctx context.Context, w io.Writer, activities []models.Activity) error {
+	return writeJSONValue(w, activities)
+}
+
+// Returns the value a listing is marshalled as, its original API object if raw
+func listingValue(listing models.Listing, raw bool) any {
+	if raw {
+		return listing.Raw
+	}
+
+	return listing
+}
+
+// Marshals a value to JSON and writes it to w
+func writeJSONValue(w io.Writer, v any) error {
+	json, err := json.Marshal(v) // Marshal JSON
+
+	if err != nil { // Error check
+		return err
+	}
+
+	// Write output
+	_, err = w.Write(json)
+
+	return err
+}
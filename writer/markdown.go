@@ -0,0 +1,119 @@
+package writer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(Format{
+		Name:        "markdown",
+		Extensions:  []string{".md", ".markdown"},
+		Description: "GitHub-flavored markdown with a table per collection, for chat and pull requests",
+		New:         func(opts Options) Writer { return markdownWriter{opts: opts} },
+	})
+}
+
+// Writes listings as a markdown report
+type markdownWriter struct {
+	opts Options
+}
+
+func (m markdownWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	var b strings.Builder
+
+	// Header with the fetch time and query parameters
+	b.WriteString("# NFT Listings\n\n")
+	fmt.Fprintf(&b, "**Fetched:** %s  \n", m.opts.fetchedAt().UTC().Format("2006-01-02 15:04:05 MST"))
+	for _, param := range m.opts.parameters() {
+		fmt.Fprintf(&b, "**%s:** %s  \n", param.Name, escapeMarkdown(param.Value))
+	}
+
+	// Table of each collection
+	summaries := stats.Summarize(listings)
+	for i, group := range groupByCollection(listings) {
+		summary := summaries[i]
+
+		fmt.Fprintf(&b, "\n## %s\n\n", escapeMarkdown(summary.Collection))
+		fmt.Fprintf(&b, "%d listings, floor %s SOL, median %s SOL", summary.Count, formatPrice(summary.Floor), strconv.FormatFloat(summary.Median, 'f', 4, 64))
+
+		// First listings of the --sort order, else the cheapest listings only
+		if m.opts.Top > 0 && m.opts.Top < len(group) {
+			if m.opts.Sort != "" {
+				group = group[:m.opts.Top]
+				fmt.Fprintf(&b, " (showing the first %d by %s)", m.opts.Top, m.opts.Sort)
+			} else {
+				group = slices.SortedStableFunc(slices.Values(group), func(a, b models.Listing) int { return cmp.Compare(a.Price, b.Price) })[:m.opts.Top]
+				fmt.Fprintf(&b, " (showing the %d cheapest)", m.opts.Top)
+			}
+		}
+		b.WriteString("\n\n")
+
+		b.WriteString("| # | Price (SOL) | Seller | Mint |\n")
+		b.WriteString("|--:|------------:|--------|------|\n")
+		for j, listing := range group {
+			fmt.Fprintf(&b, "| %d | %s | %s | %s |\n", j+1, formatPrice(listing.Price), markdownCode(listing.Seller), markdownCode(listing.Mint))
+		}
+	}
+
+	if len(listings) == 0 {
+		b.WriteString("\nNo listings.\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (m markdownWriter) WriteStats(ctx context.Context, w io.Writer, collections []models.CollectionStats) error {
+	var b strings.Builder
+
+	// Header with the fetch time
+	b.WriteString("# Collection Stats\n\n")
+	fmt.Fprintf(&b, "**Fetched:** %s\n", m.opts.fetchedAt().UTC().Format("2006-01-02 15:04:05 MST"))
+
+	if len(collections) == 0 {
+		b.WriteString("\nNo collections.\n")
+	} else {
+		b.WriteString("\n| Collection | Floor (SOL) | Listed | Avg 24h (SOL) | Volume (SOL) |\n")
+		b.WriteString("|------------|------------:|-------:|--------------:|-------------:|\n")
+		for _, collection := range collections {
+			fmt.Fprintf(&b, "| %s | %s | %d | %s | %s |\n", escapeMarkdown(collection.Collection), formatPrice(collection.FloorPrice), collection.ListedCount,
+				strconv.FormatFloat(collection.AvgPrice24h, 'f', 4, 64), strconv.FormatFloat(collection.TotalVolume, 'f', 2, 64))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// Escapes characters with a meaning in markdown text and table cells
+func escapeMarkdown(text string) string {
+	var b strings.Builder
+
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<>|#~", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Formats an address as inline code, so it can be copied as is
+func markdownCode(text string) string {
+	if text == "" || strings.ContainsAny(text, "`|") {
+		return escapeMarkdown(text)
+	}
+
+	return "`" + text + "`"
+}
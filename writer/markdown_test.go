@@ -0,0 +1,154 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMarkdownWrite writes reports with and without a top limit and compares them with the expected output
+func TestMarkdownWrite(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.5, Mint: "mint1"},
+		{Collection: "degods", Seller: "seller2", Price: 4.5, Mint: "mint2"},
+		{Collection: "degods", Seller: "seller3", Price: 7, Mint: "mint3"},
+		{Collection: "y00ts|x", Seller: "seller4", Price: 1, Mint: "mint4"},
+	}
+	opts := Options{
+		Query:     httpfetcher.GetListingsOpts{Limit: 3, MinPrice: 1, MaxPrice: 10, Desc: true},
+		Symbols:   []string{"degods", "y00ts|x"},
+		FetchedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	header := strings.Join([]string{
+		"# NFT Listings",
+		"",
+		"**Fetched:** 2024-01-02 03:04:05 UTC  ",
+		"**Collections:** degods, y00ts\\|x  ",
+		"**Limit:** 3  ",
+		"**Price range:** 1 - 10 SOL  ",
+		"**Sort:** Price descending  ",
+		"",
+		"",
+	}, "\n")
+
+	// Test table
+	var tests = []struct {
+		name string
+		top  int
+		want string
+	}{
+		{
+			name: "Every listing",
+			want: header + strings.Join([]string{
+				"## degods",
+				"",
+				"3 listings, floor 4.5 SOL, median 5.5000 SOL",
+				"",
+				"| # | Price (SOL) | Seller | Mint |",
+				"|--:|------------:|--------|------|",
+				"| 1 | 5.5 | `seller1` | `mint1` |",
+				"| 2 | 4.5 | `seller2` | `mint2` |",
+				"| 3 | 7 | `seller3` | `mint3` |",
+				"",
+				"## y00ts\\|x",
+				"",
+				"1 listings, floor 1 SOL, median 1.0000 SOL",
+				"",
+				"| # | Price (SOL) | Seller | Mint |",
+				"|--:|------------:|--------|------|",
+				"| 1 | 1 | `seller4` | `mint4` |",
+				"",
+			}, "\n"),
+		},
+		{
+			name: "Top 2",
+			top:  2,
+			want: header + strings.Join([]string{
+				"## degods",
+				"",
+				"3 listings, floor 4.5 SOL, median 5.5000 SOL (showing the 2 cheapest)",
+				"",
+				"| # | Price (SOL) | Seller | Mint |",
+				"|--:|------------:|--------|------|",
+				"| 1 | 4.5 | `seller2` | `mint2` |",
+				"| 2 | 5.5 | `seller1` | `mint1` |",
+				"",
+				"## y00ts\\|x",
+				"",
+				"1 listings, floor 1 SOL, median 1.0000 SOL",
+				"",
+				"| # | Price (SOL) | Seller | Mint |",
+				"|--:|------------:|--------|------|",
+				"| 1 | 1 | `seller4` | `mint4` |",
+				"",
+			}, "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			opts := opts
+			opts.Top = tt.top
+
+			mf, _ := Lookup("markdown")
+			if err := mf.New(opts).Write(context.Background(), &buf, input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("Got:\n%s\nwanted:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestMarkdownWriteSorted writes a report of listings sorted by rank, checking the rank range, sort order and top listings
+func TestMarkdownWriteSorted(t *testing.T) {
+	// Listings in --sort rank order
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller3", Price: 7, Mint: "mint3", Rank: 12},
+		{Collection: "degods", Seller: "seller1", Price: 5.5, Mint: "mint1", Rank: 40},
+		{Collection: "degods", Seller: "seller2", Price: 4.5, Mint: "mint2", Rank: 300},
+	}
+	opts := Options{
+		Query:     httpfetcher.GetListingsOpts{MinRank: 10, MaxRank: 500},
+		Symbols:   []string{"degods"},
+		FetchedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Sort:      "rank",
+		Top:       2,
+	}
+	want := strings.Join([]string{
+		"# NFT Listings",
+		"",
+		"**Fetched:** 2024-01-02 03:04:05 UTC  ",
+		"**Collections:** degods  ",
+		"**Rank range:** 10 - 500  ",
+		"**Sort:** Rank ascending (rarest first), across collections  ",
+		"",
+		"## degods",
+		"",
+		"3 listings, floor 4.5 SOL, median 5.5000 SOL (showing the first 2 by rank)",
+		"",
+		"| # | Price (SOL) | Seller | Mint |",
+		"|--:|------------:|--------|------|",
+		"| 1 | 7 | `seller3` | `mint3` |",
+		"| 2 | 5.5 | `seller1` | `mint1` |",
+		"",
+	}, "\n")
+
+	var buf bytes.Buffer
+	mf, _ := Lookup("markdown")
+	if err := mf.New(opts).Write(context.Background(), &buf, input); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if buf.String() != want {
+		t.Errorf("Got:\n%s\nwanted:\n%s", buf.String(), want)
+	}
+}
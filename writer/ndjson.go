@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mantas9/listings/models"
+)
+
+func init() {
+	Register(Format{
+		Name:        "ndjson",
+		Extensions:  []string{".ndjson", ".jsonl"},
+		Description: "One JSON object per line, written as soon as each collection is fetched",
+		Streaming:   true,
+		New:         func(opts Options) Writer { return ndjsonWriter{raw: opts.Raw} },
+	})
+}
+
+// Writes listings as newline-delimited JSON
+type ndjsonWriter struct {
+	raw bool // Write original API objects
+}
+
+func (n ndjsonWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	stream := &ndjsonStream{enc: json.NewEncoder(w), raw: n.raw}
+
+	return stream.WriteBatch(ctx, listings)
+}
+
+func (ndjsonWriter) WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error {
+	return writeJSONLines(w, stats)
+}
+
+func (ndjsonWriter) WriteActivities(ctx context.Context, w io.Writer, activities []models.Activity) error {
+	return writeJSONLines(w, activities)
+}
+
+// Writes each value as a JSON line
+func writeJSONLines[T any](w io.Writer, values []T) error {
+	enc := json.NewEncoder(w)
+
+	for _, value := range values {
+		// Encode writes the object followed by a newline
+		if err := enc.Encode(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n ndjsonWriter) Stream(ctx context.Context, w io.Writer) (Stream, error) {
+	return &ndjsonStream{enc: json.NewEncoder(w), raw: n.raw}, nil
+}
+
+// Stream of JSON lines
+type ndjsonStream struct {
+	enc *json.Encoder
+	raw bool // Write original API objects
+}
+
+func (s *ndjsonStream) WriteBatch(ctx context.Context, listings []models.Listing) error {
+	for _, listing := range listings {
+		// Encode writes the object followed by a newline
+		if err := s.enc.Encode(listingValue(listing, s.raw)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ndjsonStream) Close() error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mantas9/listings/models"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+)
+
+func init() {
+	Register(Format{
+		Name:        "parquet",
+		Extensions:  []string{".parquet"},
+		Description: "Parquet file with a typed schema, for DuckDB, Spark and other analytics tools",
+		New:         func(opts Options) Writer { return parquetWriter{opts: opts} },
+	})
+}
+
+// Default compression codec of parquet exports
+const DefaultCompression = "snappy"
+
+// Compression codecs of parquet exports by name
+var parquetCodecs = map[string]compress.Codec{
+	"none":   &parquet.Uncompressed,
+	"snappy": &parquet.Snappy,
+	"gzip":   &parquet.Gzip,
+	"zstd":   &parquet.Zstd,
+	"lz4":    &parquet.Lz4Raw,
+	"brotli": &parquet.Brotli,
+}
+
+// Row of a parquet export, mirroring models.Listing with typed columns
+type parquetListing struct {
+	Collection string    `parquet:"collection,dict"`
+	Seller     string    `parquet:"seller"`
+	Price      float64   `parquet:"price"`
+	Mint       string    `parquet:"mintAddress"`
+	FetchedAt  time.Time `parquet:"fetched_at,timestamp(millisecond)"`
+}
+
+// Row of a parquet export of collection stats, mirroring models.CollectionStats
+type parquetStats struct {
+	Collection  string    `parquet:"collection"`
+	FloorPrice  float64   `parquet:"floorPrice"`
+	ListedCount int64     `parquet:"listedCount"`
+	AvgPrice24h float64   `parquet:"avgPrice24h"`
+	TotalVolume float64   `parquet:"totalVolume"`
+	FetchedAt   time.Time `parquet:"fetched_at,timestamp(millisecond)"`
+}
+
+// Writes listings as a parquet file
+type parquetWriter struct {
+	opts Options
+}
+
+func (p parquetWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	// Rows, all stamped with the same fetch time
+	fetchedAt := p.opts.fetchedAt().UTC()
+	rows := make([]parquetListing, len(listings))
+	for i, listing := range listings {
+		rows[i] = parquetListing{
+			Collection: listing.Collection,
+			Seller:     listing.Seller,
+			Price:      listing.Price,
+			Mint:       listing.Mint,
+			FetchedAt:  fetchedAt,
+		}
+	}
+
+	return writeParquet(w, p.opts, rows)
+}
+
+func (p parquetWriter) WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error {
+	// Rows, all stamped with the same fetch time
+	fetchedAt := p.opts.fetchedAt().UTC()
+	rows := make([]parquetStats, len(stats))
+	for i, collection := range stats {
+		rows[i] = parquetStats{
+			Collection:  collection.Collection,
+			FloorPrice:  collection.FloorPrice,
+			ListedCount: int64(collection.ListedCount),
+			AvgPrice24h: collection.AvgPrice24h,
+			TotalVolume: collection.TotalVolume,
+			FetchedAt:   fetchedAt,
+		}
+	}
+
+	return writeParquet(w, p.opts, rows)
+}
+
+// Writes rows as a parquet file with the compression and row group size of opts
+func writeParquet[T any](w io.Writer, opts Options, rows []T) error {
+	// Compression codec
+	name := strings.ToLower(opts.Compression)
+	if name == "" {
+		name = DefaultCompression
+	}
+	codec, ok := parquetCodecs[name]
+	if !ok {
+		return fmt.Errorf("unknown compression %q", opts.Compression)
+	}
+
+	options := []parquet.WriterOption{parquet.Compression(codec)}
+	if opts.RowGroupSize > 0 {
+		options = append(options, parquet.MaxRowsPerRowGroup(int64(opts.RowGroupSize)))
+	}
+
+	pw := parquet.NewGenericWriter[T](w, options...)
+
+	if _, err := pw.Write(rows); err != nil {
+		return err
+	}
+
+	// Write the footer
+	return pw.Close()
+}
+
+// Returns the names of the supported parquet compression codecs, sorted
+func Compressions() []string {
+	names := []string{}
+	for name := range parquetCodecs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
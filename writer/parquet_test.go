@@ -0,0 +1,125 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"mantas9/listings/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// TestParquetWrite writes listings with different options and reads them back, checking rows, schema and row groups
+func TestParquetWrite(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.2361, Mint: "mint1"},
+		{Collection: "degods", Seller: "seller2", Price: 6, Mint: "mint2"},
+		{Collection: "y00ts", Seller: "seller3", Price: 1.5, Mint: "mint3"},
+	}
+
+	// Test table
+	var tests = []struct {
+		name          string
+		opts          Options
+		wantRowGroups int
+		wantCodec     format.CompressionCodec
+		expectErr     bool
+	}{
+		{name: "Defaults", opts: Options{FetchedAt: fetchedAt}, wantRowGroups: 1, wantCodec: format.Snappy},
+		{name: "Zstd, a row per group", opts: Options{FetchedAt: fetchedAt, Compression: "ZSTD", RowGroupSize: 1}, wantRowGroups: 3, wantCodec: format.Zstd},
+		{name: "Uncompressed", opts: Options{FetchedAt: fetchedAt, Compression: "none", RowGroupSize: 2}, wantRowGroups: 2, wantCodec: format.Uncompressed},
+		{name: "Unknown compression", opts: Options{Compression: "rar"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			pf, _ := Lookup("parquet")
+			err := pf.New(tt.opts).Write(context.Background(), &buf, input)
+
+			// Error check
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, but no error was returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("Error opening written file: %v", err)
+			}
+
+			// Validate column types
+			schema := file.Schema()
+			if price, _ := schema.Lookup("price"); price.Node.Type().Kind() != parquet.Double {
+				t.Errorf("Got price type %v, wanted DOUBLE", price.Node.Type())
+			}
+			if fetched, _ := schema.Lookup("fetched_at"); fetched.Node.Type().LogicalType() == nil || fetched.Node.Type().LogicalType().Timestamp == nil {
+				t.Errorf("Got fetched_at type %v, wanted a timestamp", fetched.Node.Type())
+			}
+
+			// Validate row groups, compression and dictionary encoding
+			groups := file.Metadata().RowGroups
+			if len(groups) != tt.wantRowGroups {
+				t.Errorf("Got %d row groups, wanted %d", len(groups), tt.wantRowGroups)
+			}
+			for _, column := range groups[0].Columns {
+				if column.MetaData.Codec != tt.wantCodec {
+					t.Errorf("Column %v: got codec %v, wanted %v", column.MetaData.PathInSchema, column.MetaData.Codec, tt.wantCodec)
+				}
+				if column.MetaData.PathInSchema[0] == "collection" && column.MetaData.DictionaryPageOffset == 0 {
+					t.Errorf("Column collection is not dictionary encoded")
+				}
+			}
+
+			// Validate rows
+			rows, err := parquet.Read[parquetListing](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("Error reading rows: %v", err)
+			}
+			want := []parquetListing{}
+			for _, listing := range input {
+				want = append(want, parquetListing{Collection: listing.Collection, Seller: listing.Seller, Price: listing.Price, Mint: listing.Mint, FetchedAt: fetchedAt})
+			}
+			if !reflect.DeepEqual(rows, want) {
+				t.Errorf("Got %v, wanted %v", rows, want)
+			}
+		})
+	}
+}
+
+// TestParquetWriteStats writes collection stats and reads them back
+func TestParquetWriteStats(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := []models.CollectionStats{
+		{Collection: "degods", FloorPrice: 5.3885, ListedCount: 412, AvgPrice24h: 5.6, TotalVolume: 1234567},
+		{Collection: "y00ts", FloorPrice: 1.5, ListedCount: 7},
+	}
+
+	var buf bytes.Buffer
+	pf, _ := Lookup("parquet")
+	if err := pf.New(Options{FetchedAt: fetchedAt}).(StatsWriter).WriteStats(context.Background(), &buf, input); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rows, err := parquet.Read[parquetStats](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Error reading rows: %v", err)
+	}
+	want := []parquetStats{
+		{Collection: "degods", FloorPrice: 5.3885, ListedCount: 412, AvgPrice24h: 5.6, TotalVolume: 1234567, FetchedAt: fetchedAt},
+		{Collection: "y00ts", FloorPrice: 1.5, ListedCount: 7, FetchedAt: fetchedAt},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Got %v, wanted %v", rows, want)
+	}
+}
@@ -0,0 +1,229 @@
+package writer
+
+import (
+	"fmt"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Writer options, describing the run which produced the listings
+type Options struct {
+	Query     httpfetcher.GetListingsOpts // Query parameters shared by every collection
+	Symbols   []string                    // Fetched collections
+	FetchedAt time.Time                   // Start of the fetch (default - now)
+
+	Compression  string             // Compression codec (parquet, default - DefaultCompression)
+	RowGroupSize int                // Maximum amount of rows per row group (parquet, default - unlimited)
+	Color        bool               // Colour output with ANSI escape sequences (table)
+	Top          int                // Amount of cheapest (or first with Sort) listings shown per collection (markdown, default - all)
+	Template     *template.Template // Template rendering the listings (template, see ParseTemplate)
+	Fields       []string           // Optional listing fields added as columns (csv, see formatter.ListingFields)
+	Sort         string             // Order of listings across collections, see formatter.SortOrders ("" - the API's price order)
+	Raw          bool               // Write the original API object of each listing (json, ndjson)
+}
+
+// Returns FetchedAt, defaulting to now
+func (o Options) fetchedAt() time.Time {
+	if o.FetchedAt.IsZero() {
+		return time.Now()
+	}
+
+	return o.FetchedAt
+}
+
+// Name and value of a query parameter, as shown in reports
+type parameter struct {
+	Name  string
+	Value string
+}
+
+// Returns the collections and the query parameters which were given
+func (o Options) parameters() []parameter {
+	query := o.Query
+	res := []parameter{{Name: "Collections", Value: strings.Join(o.Symbols, ", ")}}
+
+	if query.All {
+		res = append(res, parameter{Name: "All listings", Value: "yes"})
+	}
+	if query.Limit > 0 {
+		res = append(res, parameter{Name: "Limit", Value: strconv.FormatInt(query.Limit, 10)})
+	}
+	if query.Offset > 0 {
+		res = append(res, parameter{Name: "Offset", Value: strconv.FormatInt(query.Offset, 10)})
+	}
+
+	// Price range
+	switch {
+	case query.MinPrice > 0 && query.MaxPrice > 0:
+		res = append(res, parameter{Name: "Price range", Value: formatPrice(query.MinPrice) + " - " + formatPrice(query.MaxPrice) + " SOL"})
+	case query.MinPrice > 0:
+		res = append(res, parameter{Name: "Price range", Value: "at least " + formatPrice(query.MinPrice) + " SOL"})
+	case query.MaxPrice > 0:
+		res = append(res, parameter{Name: "Price range", Value: "at most " + formatPrice(query.MaxPrice) + " SOL"})
+	}
+
+	// Rank range
+	switch {
+	case query.MinRank > 0 && query.MaxRank > 0:
+		res = append(res, parameter{Name: "Rank range", Value: strconv.FormatInt(query.MinRank, 10) + " - " + strconv.FormatInt(query.MaxRank, 10)})
+	case query.MinRank > 0:
+		res = append(res, parameter{Name: "Rank range", Value: "at least " + strconv.FormatInt(query.MinRank, 10)})
+	case query.MaxRank > 0:
+		res = append(res, parameter{Name: "Rank range", Value: "at most " + strconv.FormatInt(query.MaxRank, 10)})
+	}
+
+	return append(res, parameter{Name: "Sort", Value: o.sortOrder()})
+}
+
+// Describes the order of listings, the --sort order if given, else the API's price order
+func (o Options) sortOrder() string {
+	switch o.Sort {
+	case "price":
+		return "Price ascending, across collections"
+	case "rank":
+		return "Rank ascending (rarest first), across collections"
+	case "price-per-rank":
+		return "Price × rank ascending (cheap rare listings first), across collections"
+	}
+
+	if o.Query.Desc {
+		return "Price descending"
+	}
+
+	return "Price ascending"
+}
+
+// Export format registered with Register
+type Format struct {
+	Name        string               // Name given with --format
+	Extensions  []string             // File extensions including the dot, the first one is the default
+	Description string               // One line description
+	Streaming   bool                 // Collections are written as soon as they are fetched (New must return a StreamWriter)
+	FileOnly    bool                 // Output must be a file path, not stdout (New must return a FileWriter)
+	Stdout      bool                 // Written to stdout unless an output file is given
+	New         func(Options) Writer // Creates a writer of the format
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Format{} // Registered formats by name
+)
+
+// Registers an export format. Panics if a format with the same name is already registered
+func Register(format Format) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[format.Name]; ok {
+		panic(fmt.Sprintf("writer: format %q registered twice", format.Name))
+	}
+
+	registry[format.Name] = format
+}
+
+// Returns the format with the given name
+func Lookup(name string) (Format, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	format, ok := registry[strings.ToLower(name)]
+
+	return format, ok
+}
+
+// Returns the format handling the extension of path
+func ForPath(path string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return Format{}, false
+	}
+
+	for _, format := range Formats() {
+		if slices.Contains(format.Extensions, ext) {
+			return format, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// Returns every registered format, sorted by name
+func Formats() []Format {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	res := []Format{}
+	for _, format := range registry {
+		res = append(res, format)
+	}
+
+	slices.SortFunc(res, func(a, b Format) int { return strings.Compare(a.Name, b.Name) })
+
+	return res
+}
+
+// Returns the names of every registered format, sorted
+func Names() []string {
+	names := []string{}
+	for _, format := range Formats() {
+		names = append(names, format.Name)
+	}
+
+	return names
+}
+
+// Returns the default file name of the format, e.g. listings.csv
+func (f Format) DefaultFilename() string {
+	return f.Filename("listings")
+}
+
+// Returns a file name with the default extension of the format, e.g. stats.csv for base "stats"
+func (f Format) Filename(base string) string {
+	if len(f.Extensions) == 0 {
+		return base + "." + f.Name
+	}
+
+	return base + f.Extensions[0]
+}
+
+// Tells whether writers of the format can export collection stats
+func (f Format) WritesStats() bool {
+	_, ok := f.New(Options{}).(StatsWriter)
+
+	return ok
+}
+
+// Tells whether writers of the format can export collection activities
+func (f Format) WritesActivities() bool {
+	_, ok := f.New(Options{}).(ActivityWriter)
+
+	return ok
+}
+
+// Returns the names of every registered format which can export collection stats, sorted
+func StatsNames() []string {
+	return namesWhere(Format.WritesStats)
+}
+
+// Returns the names of every registered format which can export collection activities, sorted
+func ActivityNames() []string {
+	return namesWhere(Format.WritesActivities)
+}
+
+// Returns the names of registered formats matching a condition, sorted
+func namesWhere(match func(Format) bool) []string {
+	names := []string{}
+	for _, format := range Formats() {
+		if match(format) {
+			names = append(names, format.Name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestLookup calls Lookup and ForPath with registered and unknown formats
+func TestLookup(t *testing.T) {
+	// Test table
+	var tests = []struct {
+		name   string
+		lookup func() (Format, bool)
+		want   string // Expected format name, empty if none
+	}{
+		{name: "By name", lookup: func() (Format, bool) { return Lookup("json") }, want: "json"},
+		{name: "By name, uppercase", lookup: func() (Format, bool) { return Lookup("CSV") }, want: "csv"},
+		{name: "Unknown name", lookup: func() (Format, bool) { return Lookup("yaml") }},
+		{name: "By path", lookup: func() (Format, bool) { return ForPath("out/listings.json") }, want: "json"},
+		{name: "By path, uppercase", lookup: func() (Format, bool) { return ForPath("LISTINGS.CSV") }, want: "csv"},
+		{name: "Second extension", lookup: func() (Format, bool) { return ForPath("listings.jsonl") }, want: "ndjson"},
+		{name: "Unknown extension", lookup: func() (Format, bool) { return ForPath("listings.dat") }},
+		{name: "No extension", lookup: func() (Format, bool) { return ForPath("listings") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := tt.lookup()
+
+			if ok != (tt.want != "") || format.Name != tt.want {
+				t.Errorf("Got %q (%v), wanted %q", format.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+// TestRegister checks that every format is listed once and that registering a name twice panics
+func TestRegister(t *testing.T) {
+	names := Names()
+	if !slices.IsSorted(names) || !slices.Contains(names, "csv") || !slices.Contains(names, "json") {
+		t.Errorf("Unexpected formats %v", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic registering csv twice")
+		}
+	}()
+	Register(Format{Name: "csv"})
+}
+
+// TestStatsNames checks which formats can export collection stats and their default file names
+func TestStatsNames(t *testing.T) {
+	names := StatsNames()
+	for _, name := range []string{"csv", "json", "ndjson", "table", "markdown", "template", "xlsx", "parquet"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("Format %s should write stats, got %v", name, names)
+		}
+	}
+	for _, name := range []string{"html", "sqlite"} {
+		if slices.Contains(names, name) {
+			t.Errorf("Format %s should not write stats, got %v", name, names)
+		}
+	}
+
+	// Test table of file names
+	var tests = []struct {
+		format string
+		want   string
+	}{
+		{format: "csv", want: "stats.csv"},
+		{format: "ndjson", want: "stats.ndjson"},
+		{format: "template", want: "stats.template"},
+	}
+
+	for _, tt := range tests {
+		format, _ := Lookup(tt.format)
+		if got := format.Filename("stats"); got != tt.want {
+			t.Errorf("Got file name %s, wanted %s", got, tt.want)
+		}
+	}
+}
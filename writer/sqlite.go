@@ -0,0 +1,286 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"mantas9/listings/models"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver, registered as "sqlite"
+)
+
+func init() {
+	Register(Format{
+		Name:        "sqlite",
+		Extensions:  []string{".db", ".sqlite", ".sqlite3"},
+		Description: "SQLite database, upserting listings by mint address and recording every run",
+		FileOnly:    true,
+		New:         func(opts Options) Writer { return sqliteWriter{opts: opts} },
+	})
+}
+
+// Column of the listings table holding the primary key
+const sqliteKeyColumn = "mint_address"
+
+// Table recording the parameters of every run
+const sqliteRunsTable = `CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	fetched_at TEXT NOT NULL,
+	collections TEXT NOT NULL,
+	"limit" INTEGER,
+	"offset" INTEGER,
+	min_price REAL,
+	max_price REAL,
+	min_rank INTEGER,
+	max_rank INTEGER,
+	sort TEXT,
+	sort_direction TEXT NOT NULL,
+	all_pages INTEGER NOT NULL,
+	listings INTEGER NOT NULL
+)`
+
+// Writes listings to a SQLite database
+type sqliteWriter struct {
+	opts Options
+}
+
+// Column of the listings table
+type sqliteColumn struct {
+	name  string // Column name
+	typ   string // SQLite type
+	field int    // Index of the models.Listing field
+}
+
+func (sqliteWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	return ErrFileOnly
+}
+
+func (s sqliteWriter) WriteFile(ctx context.Context, path string, listings []models.Listing) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil { // Error check
+		return err
+	}
+	defer db.Close()
+
+	// Write everything or nothing
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	columns := listingColumns()
+
+	if err := createSQLiteTables(ctx, tx, columns); err != nil {
+		return err
+	}
+
+	fetchedAt := s.opts.fetchedAt().UTC().Format(time.RFC3339)
+
+	if err := s.insertRun(ctx, tx, fetchedAt, len(listings)); err != nil {
+		return err
+	}
+
+	if err := upsertListings(ctx, tx, columns, fetchedAt, listings); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// Columns added to the runs table after its first version, in table order
+var sqliteRunsAddedColumns = []sqliteColumn{
+	{name: "min_rank", typ: "INTEGER"},
+	{name: "max_rank", typ: "INTEGER"},
+	{name: "sort", typ: "TEXT"},
+}
+
+// Creates the listings and runs tables, adding columns missing from tables of older versions
+func createSQLiteTables(ctx context.Context, tx *sql.Tx, columns []sqliteColumn) error {
+	// Listings table
+	defs := []string{}
+	for _, col := range columns {
+		def := fmt.Sprintf("%s %s NOT NULL", col.name, col.typ)
+		if col.name == sqliteKeyColumn {
+			def += " PRIMARY KEY"
+		}
+		defs = append(defs, def)
+	}
+	defs = append(defs, "fetched_at TEXT NOT NULL")
+
+	if _, err := tx.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS listings (\n\t"+strings.Join(defs, ",\n\t")+"\n)"); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, sqliteRunsTable); err != nil {
+		return err
+	}
+
+	// Add missing columns
+	existing, err := sqliteTableColumns(ctx, tx, "listings")
+	if err != nil {
+		return err
+	}
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE listings ADD COLUMN %s %s", col.name, col.typ)); err != nil {
+			return err
+		}
+	}
+
+	existing, err = sqliteTableColumns(ctx, tx, "runs")
+	if err != nil {
+		return err
+	}
+	for _, col := range sqliteRunsAddedColumns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE runs ADD COLUMN %s %s", col.name, col.typ)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the names of the columns of a table
+func sqliteTableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// Records the parameters of the run
+func (s sqliteWriter) insertRun(ctx context.Context, tx *sql.Tx, fetchedAt string, count int) error {
+	query := s.opts.Query
+
+	sortDirection := "asc"
+	if query.Desc {
+		sortDirection = "desc"
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO runs (fetched_at, collections, "limit", "offset", min_price, max_price, min_rank, max_rank, sort, sort_direction, all_pages, listings)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		fetchedAt, strings.Join(s.opts.Symbols, ","),
+		nullIfZero(query.Limit), nullIfZero(query.Offset), nullIfZero(query.MinPrice), nullIfZero(query.MaxPrice),
+		nullIfZero(query.MinRank), nullIfZero(query.MaxRank), nullIfZero(s.opts.Sort),
+		sortDirection, query.All, count)
+
+	return err
+}
+
+// Inserts listings, replacing the rows of listings which are already stored
+func upsertListings(ctx context.Context, tx *sql.Tx, columns []sqliteColumn, fetchedAt string, listings []models.Listing) error {
+	names := []string{}
+	updates := []string{}
+	for _, col := range columns {
+		names = append(names, col.name)
+		if col.name != sqliteKeyColumn {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", col.name, col.name))
+		}
+	}
+	names = append(names, "fetched_at")
+	updates = append(updates, "fetched_at = excluded.fetched_at")
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO listings (%s) VALUES (?%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(names, ", "), strings.Repeat(", ?", len(names)-1), sqliteKeyColumn, strings.Join(updates, ", ")))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, listing := range listings {
+		value := reflect.ValueOf(listing)
+
+		args := []any{}
+		for _, col := range columns {
+			args = append(args, value.Field(col.field).Interface())
+		}
+		args = append(args, fetchedAt)
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the columns of the listings table, derived from the csv tags of models.Listing
+func listingColumns() []sqliteColumn {
+	columns := []sqliteColumn{}
+
+	listingType := reflect.TypeFor[models.Listing]()
+	for i := range listingType.NumField() {
+		field := listingType.Field(i)
+
+		name := field.Tag.Get("csv")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		typ := "TEXT"
+		switch field.Type.Kind() {
+		case reflect.Float32, reflect.Float64:
+			typ = "REAL"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool:
+			typ = "INTEGER"
+		}
+
+		columns = append(columns, sqliteColumn{name: snakeCase(name), typ: typ, field: i})
+	}
+
+	return columns
+}
+
+// Converts camelCase names to snake_case
+func snakeCase(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Returns nil for zero values, which mean a parameter was not given
+func nullIfZero[T int64 | float64 | string](v T) any {
+	var zero T
+	if v == zero {
+		return nil
+	}
+
+	return v
+}
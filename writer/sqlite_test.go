@@ -0,0 +1,152 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSQLiteWriteFile writes two runs with an overlapping listing to the same database and checks the upserted rows
+func TestSQLiteWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "listings.db")
+
+	format, ok := Lookup("sqlite")
+	if !ok {
+		t.Fatalf("Format sqlite is not registered")
+	}
+
+	// Runs, each writing its listings to the database
+	var runs = []struct {
+		opts     Options
+		listings []models.Listing
+	}{
+		{
+			opts: Options{Query: httpfetcher.GetListingsOpts{Limit: 2}, Symbols: []string{"degods"}, FetchedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			listings: []models.Listing{
+				{Collection: "degods", Seller: "seller1", Price: 5.2, Mint: "mint1"},
+				{Collection: "degods", Seller: "seller2", Price: 6, Mint: "mint2"},
+			},
+		},
+		{
+			opts: Options{Query: httpfetcher.GetListingsOpts{MinPrice: 1, MaxRank: 500, Desc: true}, Symbols: []string{"degods", "y00ts"}, Sort: "rank", FetchedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			listings: []models.Listing{
+				{Collection: "degods", Seller: "seller3", Price: 4.5, Mint: "mint1"}, // Relisted
+				{Collection: "y00ts", Seller: "seller4", Price: 1.5, Mint: "mint3"},
+			},
+		},
+	}
+
+	for _, run := range runs {
+		w, ok := format.New(run.opts).(FileWriter)
+		if !ok {
+			t.Fatalf("sqlite writer is not a FileWriter")
+		}
+
+		if err := w.WriteFile(context.Background(), path, run.listings); err != nil { // Error check
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	// Validate listings, upserted by mint
+	rows, err := db.Query("SELECT collection, seller, price, mint_address, fetched_at FROM listings ORDER BY mint_address")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer rows.Close()
+
+	want := []string{
+		"degods seller3 4.5 mint1 2024-01-02T00:00:00Z",
+		"degods seller2 6 mint2 2024-01-01T00:00:00Z",
+		"y00ts seller4 1.5 mint3 2024-01-02T00:00:00Z",
+	}
+	got := []string{}
+	for rows.Next() {
+		var listing models.Listing
+		var fetchedAt string
+
+		if err := rows.Scan(&listing.Collection, &listing.Seller, &listing.Price, &listing.Mint, &fetchedAt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s %s %v %s %s", listing.Collection, listing.Seller, listing.Price, listing.Mint, fetchedAt))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Got listings %v, wanted %v", got, want)
+	}
+
+	// Validate recorded runs
+	var count int
+	var collections, sortDirection string
+	var limit, minRank, maxRank sql.NullInt64
+	var minPrice sql.NullFloat64
+	var sort sql.NullString
+	if err := db.QueryRow(`SELECT COUNT(*) FROM runs`).Scan(&count); err != nil || count != 2 {
+		t.Errorf("Got %d runs (%v), wanted 2", count, err)
+	}
+	err = db.QueryRow(`SELECT collections, "limit", min_price, min_rank, max_rank, sort, sort_direction FROM runs ORDER BY id DESC LIMIT 1`).
+		Scan(&collections, &limit, &minPrice, &minRank, &maxRank, &sort, &sortDirection)
+	if err != nil || collections != "degods,y00ts" || limit.Valid || minPrice.Float64 != 1 || minRank.Valid || maxRank.Int64 != 500 || sort.String != "rank" || sortDirection != "desc" {
+		t.Errorf("Got run %s %v %v %v %v %v %s (%v)", collections, limit, minPrice, minRank, maxRank, sort, sortDirection, err)
+	}
+}
+
+// TestSQLiteWrite checks that the sqlite writer refuses to write to a stream
+func TestSQLiteWrite(t *testing.T) {
+	format, _ := Lookup("sqlite")
+
+	err := format.New(Options{}).Write(context.Background(), &bytes.Buffer{}, []models.Listing{})
+	if !errors.Is(err, ErrFileOnly) {
+		t.Errorf("Got error %v, wanted %v", err, ErrFileOnly)
+	}
+}
+
+// TestSQLiteWriteFileOldRuns writes to a database with a runs table of an older version, which lacks the rank and sort columns
+func TestSQLiteWriteFileOldRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "listings.db")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		fetched_at TEXT NOT NULL,
+		collections TEXT NOT NULL,
+		"limit" INTEGER,
+		"offset" INTEGER,
+		min_price REAL,
+		max_price REAL,
+		sort_direction TEXT NOT NULL,
+		all_pages INTEGER NOT NULL,
+		listings INTEGER NOT NULL
+	)`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	format, _ := Lookup("sqlite")
+	w := format.New(Options{Query: httpfetcher.GetListingsOpts{MinRank: 10}, Symbols: []string{"degods"}}).(FileWriter)
+
+	if err := w.WriteFile(context.Background(), path, []models.Listing{{Collection: "degods", Mint: "mint1"}}); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var minRank sql.NullInt64
+	if err := db.QueryRow(`SELECT min_rank FROM runs`).Scan(&minRank); err != nil || minRank.Int64 != 10 {
+		t.Errorf("Got min_rank %v (%v), wanted 10", minRank, err)
+	}
+}
@@ -0,0 +1,215 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	Register(Format{
+		Name:        "table",
+		Extensions:  []string{".txt"},
+		Description: "Aligned text table with per-collection subtotals, for terminals",
+		Stdout:      true,
+		New:         func(opts Options) Writer { return tableWriter{color: opts.Color} },
+	})
+}
+
+// Characters of addresses kept on each side of the ellipsis
+const tableAddressChars = 4
+
+// ANSI escape sequences of table colours
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Writes listings as an aligned text table
+type tableWriter struct {
+	color bool // Colour output with ANSI escape sequences
+}
+
+// Column of a text table
+type tableColumn struct {
+	title string
+	right bool   // Align to the right
+	color string // ANSI colour of cells
+}
+
+// Columns of listing tables
+var tableColumns = []tableColumn{
+	{title: "COLLECTION", color: ansiCyan},
+	{title: "PRICE (SOL)", right: true, color: ansiGreen},
+	{title: "SELLER"},
+	{title: "MINT"},
+}
+
+// Columns of collection stats tables
+var statsTableColumns = []tableColumn{
+	{title: "COLLECTION", color: ansiCyan},
+	{title: "FLOOR (SOL)", right: true, color: ansiGreen},
+	{title: "LISTED", right: true},
+	{title: "AVG 24H (SOL)", right: true},
+	{title: "VOLUME (SOL)", right: true},
+}
+
+func (t tableWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	groups := groupByCollection(listings)
+
+	// Cells of every collection's rows
+	cells := make([][][]string, len(groups))
+	for i, group := range groups {
+		for _, listing := range group {
+			cells[i] = append(cells[i], []string{listing.Collection, formatPrice(listing.Price), shortAddress(listing.Seller, tableAddressChars), shortAddress(listing.Mint, tableAddressChars)})
+		}
+	}
+	widths := tableWidths(tableColumns, slices.Concat(cells...))
+
+	var b strings.Builder
+
+	// Header
+	t.writeHeader(&b, tableColumns, widths)
+
+	// Rows and subtotal of each collection
+	summaries := stats.Summarize(listings)
+	for i, rows := range cells {
+		for _, row := range rows {
+			t.writeRow(&b, tableColumns, row, widths, "")
+		}
+
+		summary := summaries[i]
+		total := 0.0
+		for _, listing := range groups[i] {
+			total += listing.Price
+		}
+		t.writeLine(&b, fmt.Sprintf("%s: %d listings, floor %s, median %s, total %s SOL",
+			summary.Collection, summary.Count, formatPrice(summary.Floor), strconv.FormatFloat(summary.Median, 'f', 4, 64), strconv.FormatFloat(total, 'f', 4, 64)), ansiDim)
+		b.WriteString("\n")
+	}
+
+	// Grand total
+	t.writeLine(&b, fmt.Sprintf("%d listings in %d collections", len(listings), len(groups)), ansiBold)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (t tableWriter) WriteStats(ctx context.Context, w io.Writer, collections []models.CollectionStats) error {
+	// Cells of every collection's row
+	rows := [][]string{}
+	for _, collection := range collections {
+		rows = append(rows, []string{
+			collection.Collection,
+			formatPrice(collection.FloorPrice),
+			strconv.Itoa(collection.ListedCount),
+			strconv.FormatFloat(collection.AvgPrice24h, 'f', 4, 64),
+			strconv.FormatFloat(collection.TotalVolume, 'f', 2, 64),
+		})
+	}
+	widths := tableWidths(statsTableColumns, rows)
+
+	var b strings.Builder
+
+	t.writeHeader(&b, statsTableColumns, widths)
+	for _, row := range rows {
+		t.writeRow(&b, statsTableColumns, row, widths, "")
+	}
+	t.writeLine(&b, fmt.Sprintf("%d collections", len(collections)), ansiBold)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// Returns the width of each column, fitting its title and every cell
+func tableWidths(columns []tableColumn, rows [][]string) []int {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = utf8.RuneCountInString(column.title)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	return widths
+}
+
+// Writes the bold titles of columns
+func (t tableWriter) writeHeader(b *strings.Builder, columns []tableColumn, widths []int) {
+	titles := []string{}
+	for _, column := range columns {
+		titles = append(titles, column.title)
+	}
+	t.writeRow(b, columns, titles, widths, ansiBold)
+}
+
+// Writes a row of padded cells, coloured by column unless style is given
+func (t tableWriter) writeRow(b *strings.Builder, columns []tableColumn, row []string, widths []int, style string) {
+	for i, cell := range row {
+		column := columns[i]
+
+		if i > 0 {
+			b.WriteString("  ")
+		}
+
+		// Pad before colouring, escape sequences have no width
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if i == len(row)-1 && !column.right {
+			padding = "" // No trailing spaces
+		}
+
+		color := column.color
+		if style != "" {
+			color = style
+		}
+
+		if column.right {
+			b.WriteString(padding)
+		}
+		t.writeColored(b, cell, color)
+		if !column.right {
+			b.WriteString(padding)
+		}
+	}
+
+	b.WriteString("\n")
+}
+
+// Writes a line of text in the given colour
+func (t tableWriter) writeLine(b *strings.Builder, text, color string) {
+	t.writeColored(b, text, color)
+	b.WriteString("\n")
+}
+
+// Writes text wrapped in an ANSI colour if colours are enabled
+func (t tableWriter) writeColored(b *strings.Builder, text, color string) {
+	if !t.color || color == "" {
+		b.WriteString(text)
+		return
+	}
+
+	b.WriteString(color + text + ansiReset)
+}
+
+// Shortens an address to its first and last chars characters, e.g. skyi…kheA
+func shortAddress(address string, chars int) string {
+	runes := []rune(address)
+	if len(runes) <= 2*chars+1 {
+		return address
+	}
+
+	return string(runes[:chars]) + "…" + string(runes[len(runes)-chars:])
+}
@@ -0,0 +1,67 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"mantas9/listings/models"
+	"strings"
+	"testing"
+)
+
+// TestTableWrite writes plain and coloured tables and compares them with the expected output
+func TestTableWrite(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA", Price: 5.2361, Mint: "BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"},
+		{Collection: "degods", Seller: "short", Price: 12, Mint: "mint"},
+		{Collection: "y00ts", Seller: "Ftsyq4i8Lq6ckz7yYKPG4sAJg3xDZxdyR3NvmUzTPHyj", Price: 1.5, Mint: "6KuX26FZqzqpsHDLfkXoBXbQRPEDEFZPY4BVWS1XLQE6"},
+	}
+
+	plain := strings.Join([]string{
+		"COLLECTION  PRICE (SOL)  SELLER     MINT",
+		"degods           5.2361  skyi…kheA  BJh3…feNV",
+		"degods               12  short      mint",
+		"degods: 2 listings, floor 5.2361, median 8.6181, total 17.2361 SOL",
+		"",
+		"y00ts               1.5  Ftsy…PHyj  6KuX…LQE6",
+		"y00ts: 1 listings, floor 1.5, median 1.5000, total 1.5000 SOL",
+		"",
+		"3 listings in 2 collections",
+		"",
+	}, "\n")
+
+	// Test table
+	var tests = []struct {
+		name  string
+		color bool
+		check func(got string) bool
+	}{
+		{name: "Plain", check: func(got string) bool { return got == plain }},
+		{
+			name:  "Colored",
+			color: true,
+			check: func(got string) bool {
+				// Same text once escape sequences are removed
+				stripped := got
+				for _, code := range []string{ansiReset, ansiBold, ansiDim, ansiGreen, ansiCyan} {
+					stripped = strings.ReplaceAll(stripped, code, "")
+				}
+				return stripped == plain && strings.Contains(got, ansiGreen+"5.2361"+ansiReset)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tf, _ := Lookup("table")
+			if err := tf.New(Options{Color: tt.color}).Write(context.Background(), &buf, input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !tt.check(buf.String()) {
+				t.Errorf("Got:\n%s\nwanted:\n%s", buf.String(), plain)
+			}
+		})
+	}
+}
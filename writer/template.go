@@ -0,0 +1,190 @@
+package writer
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	httpfetcher "mantas9/listings/httpFetcher"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func init() {
+	Register(Format{
+		Name:        "template",
+		Description: "Custom output rendered by a text/template given with --template or --template-string",
+		Stdout:      true,
+		New:         func(opts Options) Writer { return templateWriter{opts: opts} },
+	})
+}
+
+// Error of template exports without a template
+var ErrNoTemplate = errors.New("no template given")
+
+// Data a template is executed with
+type TemplateData struct {
+	Listings    []models.Listing            // Every listing, in command line order of collections (fetch)
+	Collections []TemplateGroup             // Listings grouped by collection (fetch)
+	Stats       []models.CollectionStats    // Stats of every collection, in command line order (stats)
+	Symbols     []string                    // Fetched collections
+	Query       httpfetcher.GetListingsOpts // Query parameters shared by every collection
+	FetchedAt   time.Time                   // Start of the fetch
+}
+
+// Listings sharing the value of a field
+type TemplateGroup struct {
+	Key      string           // Value of the grouping field, the collection symbol for TemplateData.Collections
+	Listings []models.Listing // Listings of the group, in their original order
+}
+
+// Fields listings can be sorted and grouped by in templates
+var templateFields = map[string]func(models.Listing) string{
+	"collection": func(l models.Listing) string { return l.Collection },
+	"seller":     func(l models.Listing) string { return l.Seller },
+	"mint":       func(l models.Listing) string { return l.Mint },
+}
+
+// Helper functions available in templates
+var templateFuncs = template.FuncMap{
+	"price":     formatPrice,
+	"short":     templateShort,
+	"sortBy":    templateSortBy,
+	"groupBy":   templateGroupBy,
+	"first":     templateFirst,
+	"summarize": stats.Summarize,
+	"json":      templateJSON,
+	"csv":       templateCSV,
+	"sql":       templateSQL,
+	"join":      strings.Join,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+}
+
+// Parses a template with the helper functions of template exports
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Renders listings through a template
+type templateWriter struct {
+	opts Options
+}
+
+func (t templateWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	if t.opts.Template == nil {
+		return ErrNoTemplate
+	}
+
+	data := TemplateData{
+		Listings:  listings,
+		Symbols:   t.opts.Symbols,
+		Query:     t.opts.Query,
+		FetchedAt: t.opts.fetchedAt().UTC(),
+	}
+	for _, group := range groupByCollection(listings) {
+		data.Collections = append(data.Collections, TemplateGroup{Key: group[0].Collection, Listings: group})
+	}
+
+	return t.opts.Template.Execute(w, data)
+}
+
+func (t templateWriter) WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error {
+	if t.opts.Template == nil {
+		return ErrNoTemplate
+	}
+
+	return t.opts.Template.Execute(w, TemplateData{
+		Stats:     stats,
+		Symbols:   t.opts.Symbols,
+		FetchedAt: t.opts.fetchedAt().UTC(),
+	})
+}
+
+// Shortens an address to its first and last 4 (or n) characters
+func templateShort(address string, n ...int) string {
+	chars := tableAddressChars
+	if len(n) > 0 && n[0] > 0 {
+		chars = n[0]
+	}
+
+	return shortAddress(address, chars)
+}
+
+// Returns a sorted copy of listings. The field (price, collection, seller or mint) is prefixed with "-" for descending order
+func templateSortBy(field string, listings []models.Listing) ([]models.Listing, error) {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var compare func(a, b models.Listing) int
+	if field == "price" {
+		compare = func(a, b models.Listing) int { return cmp.Compare(a.Price, b.Price) }
+	} else if value, ok := templateFields[field]; ok {
+		compare = func(a, b models.Listing) int { return cmp.Compare(value(a), value(b)) }
+	} else {
+		return nil, fmt.Errorf("sortBy: unknown field %q, expected price, collection, seller or mint", field)
+	}
+
+	if desc {
+		ascending := compare
+		compare = func(a, b models.Listing) int { return ascending(b, a) }
+	}
+
+	return slices.SortedStableFunc(slices.Values(listings), compare), nil
+}
+
+// Groups listings by a field (collection, seller or mint), in the order values first appear
+func templateGroupBy(field string, listings []models.Listing) ([]TemplateGroup, error) {
+	value, ok := templateFields[field]
+	if !ok {
+		return nil, fmt.Errorf("groupBy: unknown field %q, expected collection, seller or mint", field)
+	}
+
+	index := map[string]int{} // Index of each value's group
+	groups := []TemplateGroup{}
+	for _, listing := range listings {
+		key := value(listing)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, TemplateGroup{Key: key})
+		}
+		groups[i].Listings = append(groups[i].Listings, listing)
+	}
+
+	return groups, nil
+}
+
+// Returns the first n listings
+func templateFirst(n int, listings []models.Listing) []models.Listing {
+	return listings[:max(0, min(n, len(listings)))]
+}
+
+// Marshals a value to JSON
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+
+	return string(data), err
+}
+
+// Quotes a CSV field if needed
+func templateCSV(field string) string {
+	if !strings.ContainsAny(field, ",\"\r\n") {
+		return field
+	}
+
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
+
+// Quotes an SQL string literal
+func templateSQL(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
@@ -0,0 +1,103 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mantas9/listings/models"
+	"testing"
+	"time"
+)
+
+// TestTemplateWrite renders listings through templates using every helper function
+func TestTemplateWrite(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA", Price: 5.5, Mint: "mint1"},
+		{Collection: "y00ts", Seller: "o'brien", Price: 1, Mint: "mint2"},
+		{Collection: "degods", Seller: "seller,3", Price: 4.25, Mint: "mint3"},
+	}
+
+	// Test table
+	var tests = []struct {
+		name      string
+		template  string
+		want      string
+		expectErr bool
+	}{
+		{
+			name:     "Collections",
+			template: `{{range .Collections}}{{.Key}}: {{len .Listings}}{{"\n"}}{{end}}`,
+			want:     "degods: 2\ny00ts: 1\n",
+		},
+		{
+			name:     "SQL inserts",
+			template: `{{range .Listings}}INSERT INTO listings VALUES ({{sql .Mint}}, {{sql .Seller}}, {{price .Price}});{{"\n"}}{{end}}`,
+			want:     "INSERT INTO listings VALUES ('mint1', 'skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA', 5.5);\nINSERT INTO listings VALUES ('mint2', 'o''brien', 1);\nINSERT INTO listings VALUES ('mint3', 'seller,3', 4.25);\n",
+		},
+		{
+			name:     "CSV, sorted by descending price",
+			template: `{{range sortBy "-price" .Listings}}{{csv .Seller}},{{.Price}}{{"\n"}}{{end}}`,
+			want:     "skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA,5.5\n\"seller,3\",4.25\no'brien,1\n",
+		},
+		{
+			name:     "Cheapest per collection",
+			template: `{{range groupBy "collection" .Listings}}{{.Key}}={{range first 1 (sortBy "price" .Listings)}}{{short .Seller 3}}{{end}};{{end}}`,
+			want:     "degods=sel…r,3;y00ts=o'brien;",
+		},
+		{
+			name:     "Short addresses and helpers",
+			template: `{{short (index .Listings 0).Seller}} {{upper (join .Symbols "+")}} {{.FetchedAt.Year}}`,
+			want:     "skyi…kheA DEGODS+Y00TS 2024",
+		},
+		{
+			name:     "Summaries as JSON",
+			template: `{{json (index (summarize .Listings) 0)}}`,
+			want:     `{"collection":"degods","count":2,"floor":4.25,"median":4.875,"mean":4.875,"max":5.5}`,
+		},
+		{
+			name:      "Unknown sort field",
+			template:  `{{range sortBy "rarity" .Listings}}{{end}}`,
+			expectErr: true,
+		},
+		{
+			name:      "Unknown listing field",
+			template:  `{{range .Listings}}{{.Owner}}{{end}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate("test", tt.template)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			tf, _ := Lookup("template")
+			opts := Options{Template: tmpl, Symbols: []string{"degods", "y00ts"}, FetchedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+			err = tf.New(opts).Write(context.Background(), &buf, input)
+
+			// Error check
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error, but no error was returned")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if !tt.expectErr && buf.String() != tt.want {
+				t.Errorf("Got %q, wanted %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestTemplateWriteNoTemplate checks that template exports fail without a template
+func TestTemplateWriteNoTemplate(t *testing.T) {
+	tf, _ := Lookup("template")
+
+	if err := tf.New(Options{}).Write(context.Background(), &bytes.Buffer{}, []models.Listing{}); !errors.Is(err, ErrNoTemplate) {
+		t.Errorf("Got error %v, wanted %v", err, ErrNoTemplate)
+	}
+}
@@ -1,48 +1,83 @@
 package writer
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
+	"io"
 	"mantas9/listings/models"
 	"os"
-
-	"github.com/gocarina/gocsv"
 )
 
-// Marshals Listing data to JSON and writes output to file
-func WriteJSON(data []models.Listing, filename string) error {
-	json, err := json.Marshal(data) // Marshal JSON
+// Exports listings in a single format
+type Writer interface {
+	// Writes every listing to w
+	Write(ctx context.Context, w io.Writer, listings []models.Listing) error
+}
 
-	if err != nil { // Error check
-		return err
-	}
+// Writer which can also export listings in batches, as they are fetched
+type StreamWriter interface {
+	Writer
 
-	// Write to file
-	if err := os.WriteFile(filename, json, 0644); err != nil {
-		return err
-	}
+	// Starts a stream of batches written to w
+	Stream(ctx context.Context, w io.Writer) (Stream, error)
+}
+
+// Stream of listing batches started by a StreamWriter
+type Stream interface {
+	// Writes a batch of listings
+	WriteBatch(ctx context.Context, listings []models.Listing) error
+
+	// Finishes the output. Does not close the underlying io.Writer
+	Close() error
+}
 
-	// Print success message
-	fmt.Printf("Your selected NFT Listings' data has been written to %s successfully.\n", filename)
+// Writer which manages its own output file, e.g. a database
+type FileWriter interface {
+	Writer
 
-	return nil
+	// Writes every listing to the file at path, creating it if needed
+	WriteFile(ctx context.Context, path string, listings []models.Listing) error
+}
+
+// Writer which can also export collection stats
+type StatsWriter interface {
+	// Writes the stats of every collection to w
+	WriteStats(ctx context.Context, w io.Writer, stats []models.CollectionStats) error
+}
+
+// Writer which can also export collection activities
+type ActivityWriter interface {
+	// Writes every activity to w
+	WriteActivities(ctx context.Context, w io.Writer, activities []models.Activity) error
+}
+
+// Error of FileWriters which are asked to write to a stream
+var ErrFileOnly = errors.New("format can only be written to a file")
+
+// Marshals Listing data to JSON and writes output to file
+func WriteJSON(data []models.Listing, filename string) error {
+	return writeFile(filename, data, jsonWriter{})
 }
 
 // Write struct data to CSV file
 func WriteCSV(data []models.Listing, filename string) error {
-	// Create CSV file if exists
+	return writeFile(filename, data, csvWriter{})
+}
+
+// Creates (or truncates) a file and writes data to it with w
+func writeFile(filename string, data []models.Listing, w Writer) error {
+	// Create file, truncating it if exists
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close() // Close file at the end of writing
 
-	// Write to CSV
-	if err := gocsv.MarshalFile(&data, file); err != nil {
+	// Write to file
+	if err := w.Write(context.Background(), file, data); err != nil {
+		file.Close()
 		return err
 	}
 
-	fmt.Printf("Your selected NFT Listings' data has been written to %s successfully.\n", filename)
-
-	return nil
+	// Close file, reporting failed flushes
+	return file.Close()
 }
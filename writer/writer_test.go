@@ -1,9 +1,17 @@
 package writer
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"mantas9/listings/models"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"text/template"
+	"time"
 )
 
 // TestWriteJSON calls WriteJSON inputting Valid, Empty and Partial/Invalid struct arrays
@@ -186,3 +194,360 @@ func TestWriteCSV(t *testing.T) {
 		})
 	}
 }
+
+// TestWriters calls Write (and Stream of streaming writers) of the registered writers with an in-memory writer and compares the outputs
+func TestWriters(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA", Price: 5.2361, Mint: "BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"},
+		{Collection: "y00ts", Seller: "Ftsyq4i8Lq6ckz7yYKPG4sAJg3xDZxdyR3NvmUzTPHyj", Price: 1.5, Mint: "6KuX26FZqzqpsHDLfkXoBXbQRPEDEFZPY4BVWS1XLQE6"},
+	}
+
+	// Test table
+	var tests = []struct {
+		format string
+		input  []models.Listing
+		want   string
+	}{
+		{
+			format: "json",
+			input:  input[:1],
+			want:   `[{"collection":"degods","seller":"skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA","price":5.2361,"mintAddress":"BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"}]`,
+		},
+		{
+			format: "json",
+			input:  input,
+			want:   `[{"collection":"degods","seller":"skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA","price":5.2361,"mintAddress":"BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"},{"collection":"y00ts","seller":"Ftsyq4i8Lq6ckz7yYKPG4sAJg3xDZxdyR3NvmUzTPHyj","price":1.5,"mintAddress":"6KuX26FZqzqpsHDLfkXoBXbQRPEDEFZPY4BVWS1XLQE6"}]`,
+		},
+		{
+			format: "json",
+			input:  []models.Listing{},
+			want:   `[]`,
+		},
+		{
+			format: "ndjson",
+			input:  input,
+			want:   `{"collection":"degods","seller":"skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA","price":5.2361,"mintAddress":"BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV"}` + "\n" + `{"collection":"y00ts","seller":"Ftsyq4i8Lq6ckz7yYKPG4sAJg3xDZxdyR3NvmUzTPHyj","price":1.5,"mintAddress":"6KuX26FZqzqpsHDLfkXoBXbQRPEDEFZPY4BVWS1XLQE6"}` + "\n",
+		},
+		{
+			format: "ndjson",
+			input:  []models.Listing{},
+			want:   "",
+		},
+		{
+			format: "csv",
+			input:  input,
+			want:   "collection,seller,price,mintAddress\ndegods,skyi3dryB3a3M5Sh5CTDYput2ET7BjTvXY8SMxZkheA,5.2361,BJh3bS9gxfae6TNuwdorJ7pztVUeXhw2DnP4KVwgfeNV\ny00ts,Ftsyq4i8Lq6ckz7yYKPG4sAJg3xDZxdyR3NvmUzTPHyj,1.5,6KuX26FZqzqpsHDLfkXoBXbQRPEDEFZPY4BVWS1XLQE6\n",
+		},
+		{
+			format: "csv",
+			input:  []models.Listing{},
+			want:   "collection,seller,price,mintAddress\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, len(tt.input)), func(t *testing.T) {
+			format, ok := Lookup(tt.format)
+			if !ok {
+				t.Fatalf("Format %s is not registered", tt.format)
+			}
+			w := format.New(Options{})
+
+			// Whole export
+			var buf bytes.Buffer
+			if err := w.Write(context.Background(), &buf, tt.input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write: got %v, wanted %v", buf.String(), tt.want)
+			}
+
+			// One batch per listing
+			sw, ok := w.(StreamWriter)
+			if !ok {
+				return
+			}
+			buf.Reset()
+			stream, err := sw.Stream(context.Background(), &buf)
+			if err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, listing := range tt.input {
+				if err := stream.WriteBatch(context.Background(), []models.Listing{listing}); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Stream: got %v, wanted %v", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteFields writes listings with optional fields and in raw mode, as a whole and in batches if the writer streams
+func TestWriteFields(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.2361, Mint: "mint1", PDAAddress: "pda1", Rarity: json.RawMessage(`{"moonrank":{"rank":12}}`),
+			PriceLamports: 5236100000, Raw: json.RawMessage(`{"pdaAddress":"pda1","seller":"seller1","price":5.2361,"extra":{"img":"a.png"}}`)},
+		{Collection: "y00ts", Seller: "seller2", Price: 1.5, Mint: "mint2", PDAAddress: "pda2",
+			PriceLamports: 1500000000, Raw: json.RawMessage(`{"pdaAddress":"pda2","seller":"seller2","price":1.5}`)},
+	}
+
+	// Test table
+	var tests = []struct {
+		name   string
+		format string
+		opts   Options
+		want   string
+	}{
+		{ // Columns in the order of formatter.ListingFields
+			name:   "csv fields",
+			format: "csv",
+			opts:   Options{Fields: []string{"priceLamports", "pdaAddress", "rarity"}},
+			want: "collection,seller,price,mintAddress,pdaAddress,rarity,priceLamports\n" +
+				"degods,seller1,5.2361,mint1,pda1,\"{\"\"moonrank\"\":{\"\"rank\"\":12}}\",5236100000\n" +
+				"y00ts,seller2,1.5,mint2,pda2,,1500000000\n",
+		},
+		{
+			name:   "json raw",
+			format: "json",
+			opts:   Options{Raw: true},
+			want:   `[{"pdaAddress":"pda1","seller":"seller1","price":5.2361,"extra":{"img":"a.png"}},{"pdaAddress":"pda2","seller":"seller2","price":1.5}]`,
+		},
+		{
+			name:   "ndjson raw",
+			format: "ndjson",
+			opts:   Options{Raw: true},
+			want:   `{"pdaAddress":"pda1","seller":"seller1","price":5.2361,"extra":{"img":"a.png"}}` + "\n" + `{"pdaAddress":"pda2","seller":"seller2","price":1.5}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := Lookup(tt.format)
+			if !ok {
+				t.Fatalf("Format %s is not registered", tt.format)
+			}
+			w := format.New(tt.opts)
+
+			// Whole export
+			var buf bytes.Buffer
+			if err := w.Write(context.Background(), &buf, input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write: got %v, wanted %v", buf.String(), tt.want)
+			}
+
+			// One batch per listing
+			sw, ok := w.(StreamWriter)
+			if !ok {
+				return
+			}
+			buf.Reset()
+			stream, err := sw.Stream(context.Background(), &buf)
+			if err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, listing := range input {
+				if err := stream.WriteBatch(context.Background(), []models.Listing{listing}); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Stream: got %v, wanted %v", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteEnriched writes listings with token metadata, checking CSV attribute columns and JSON fields
+func TestWriteEnriched(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.2361, Mint: "mint1", Name: "DeGod #1", Image: "https://example.com/1.png",
+			Attributes: []models.Attribute{{TraitType: "Background", Value: "Red"}, {TraitType: "Eyes", Value: "Laser, blue"}}},
+		{Collection: "degods", Seller: "seller2", Price: 1.5, Mint: "mint2", Name: "DeGod #2",
+			Attributes: []models.Attribute{{TraitType: "Fur", Value: "Gold"}, {TraitType: "Background", Value: "Blue"}}},
+		{Collection: "y00ts", Seller: "seller3", Price: 2, Mint: "mint3"}, // Token which could not be fetched
+	}
+
+	// Test table
+	var tests = []struct {
+		format string
+		want   string
+	}{
+		{ // Trait columns in order of first appearance, empty for listings without the trait
+			format: "csv",
+			want: "collection,seller,price,mintAddress,name,image,attributes.Background,attributes.Eyes,attributes.Fur\n" +
+				"degods,seller1,5.2361,mint1,DeGod #1,https://example.com/1.png,Red,\"Laser, blue\",\n" +
+				"degods,seller2,1.5,mint2,DeGod #2,,Blue,,Gold\n" +
+				"y00ts,seller3,2,mint3,,,,,\n",
+		},
+		{
+			format: "ndjson",
+			want: `{"collection":"degods","seller":"seller1","price":5.2361,"mintAddress":"mint1","name":"DeGod #1","image":"https://example.com/1.png","attributes":[{"trait_type":"Background","value":"Red"},{"trait_type":"Eyes","value":"Laser, blue"}]}` + "\n" +
+				`{"collection":"degods","seller":"seller2","price":1.5,"mintAddress":"mint2","name":"DeGod #2","attributes":[{"trait_type":"Fur","value":"Gold"},{"trait_type":"Background","value":"Blue"}]}` + "\n" +
+				`{"collection":"y00ts","seller":"seller3","price":2,"mintAddress":"mint3"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format, ok := Lookup(tt.format)
+			if !ok {
+				t.Fatalf("Format %s is not registered", tt.format)
+			}
+
+			var buf bytes.Buffer
+			if err := format.New(Options{}).Write(context.Background(), &buf, input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Got %v, wanted %v", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteStats calls WriteStats of the text formats and compares the outputs
+func TestWriteStats(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := []models.CollectionStats{
+		{Collection: "degods", FloorPrice: 5.3885, ListedCount: 412, AvgPrice24h: 5.61234, TotalVolume: 1234567.891},
+		{Collection: "y00ts", FloorPrice: 1.5, ListedCount: 7},
+	}
+	tmpl := template.Must(ParseTemplate("test", `{{range .Stats}}{{.Collection}}={{price .FloorPrice}} {{end}}`))
+
+	// Test table
+	var tests = []struct {
+		format string
+		input  []models.CollectionStats
+		want   string
+	}{
+		{
+			format: "csv",
+			input:  input,
+			want:   "collection,floorPrice,listedCount,avgPrice24h,totalVolume\ndegods,5.3885,412,5.61234,1234567.891\ny00ts,1.5,7,0,0\n",
+		},
+		{
+			format: "json",
+			input:  input[:1],
+			want:   `[{"collection":"degods","floorPrice":5.3885,"listedCount":412,"avgPrice24h":5.61234,"totalVolume":1234567.891}]`,
+		},
+		{
+			format: "ndjson",
+			input:  input,
+			want:   `{"collection":"degods","floorPrice":5.3885,"listedCount":412,"avgPrice24h":5.61234,"totalVolume":1234567.891}` + "\n" + `{"collection":"y00ts","floorPrice":1.5,"listedCount":7,"avgPrice24h":0,"totalVolume":0}` + "\n",
+		},
+		{
+			format: "table",
+			input:  input,
+			want: strings.Join([]string{
+				"COLLECTION  FLOOR (SOL)  LISTED  AVG 24H (SOL)  VOLUME (SOL)",
+				"degods           5.3885     412         5.6123    1234567.89",
+				"y00ts               1.5       7         0.0000          0.00",
+				"2 collections",
+				"",
+			}, "\n"),
+		},
+		{
+			format: "markdown",
+			input:  input,
+			want: strings.Join([]string{
+				"# Collection Stats",
+				"",
+				"**Fetched:** 2024-01-02 03:04:05 UTC",
+				"",
+				"| Collection | Floor (SOL) | Listed | Avg 24h (SOL) | Volume (SOL) |",
+				"|------------|------------:|-------:|--------------:|-------------:|",
+				"| degods | 5.3885 | 412 | 5.6123 | 1234567.89 |",
+				"| y00ts | 1.5 | 7 | 0.0000 | 0.00 |",
+				"",
+			}, "\n"),
+		},
+		{
+			format: "markdown",
+			input:  []models.CollectionStats{},
+			want:   "# Collection Stats\n\n**Fetched:** 2024-01-02 03:04:05 UTC\n\nNo collections.\n",
+		},
+		{
+			format: "template",
+			input:  input,
+			want:   "degods=5.3885 y00ts=1.5 ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, len(tt.input)), func(t *testing.T) {
+			format, ok := Lookup(tt.format)
+			if !ok {
+				t.Fatalf("Format %s is not registered", tt.format)
+			}
+			sw, ok := format.New(Options{FetchedAt: fetchedAt, Template: tmpl}).(StatsWriter)
+			if !ok {
+				t.Fatalf("Format %s does not write stats", tt.format)
+			}
+
+			var buf bytes.Buffer
+			if err := sw.WriteStats(context.Background(), &buf, tt.input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Got:\n%v\nwanted:\n%v", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteActivities calls WriteActivities of the formats exporting activities and compares the outputs
+func TestWriteActivities(t *testing.T) {
+	input := []models.Activity{
+		{Collection: "degods", Type: "buyNow", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Price: 5.3885, Seller: "seller1", Buyer: "buyer1", Mint: "mint1", Signature: "sig1", Source: "magiceden_v2"},
+	}
+	line := `{"collection":"degods","type":"buyNow","time":"2024-01-02T03:04:05Z","price":5.3885,"seller":"seller1","buyer":"buyer1","mintAddress":"mint1","signature":"sig1","source":"magiceden_v2"}`
+
+	// Test table
+	var tests = []struct {
+		format string
+		input  []models.Activity
+		want   string
+	}{
+		{
+			format: "csv",
+			input:  input,
+			want:   "collection,type,time,price,seller,buyer,mintAddress,signature,source\ndegods,buyNow,2024-01-02T03:04:05Z,5.3885,seller1,buyer1,mint1,sig1,magiceden_v2\n",
+		},
+		{format: "json", input: input, want: "[" + line + "]"},
+		{format: "json", input: []models.Activity{}, want: "[]"},
+		{format: "ndjson", input: input, want: line + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, len(tt.input)), func(t *testing.T) {
+			format, _ := Lookup(tt.format)
+			aw, ok := format.New(Options{}).(ActivityWriter)
+			if !ok {
+				t.Fatalf("Format %s does not write activities", tt.format)
+			}
+
+			var buf bytes.Buffer
+			if err := aw.WriteActivities(context.Background(), &buf, tt.input); err != nil { // Error check
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Got:\n%v\nwanted:\n%v", buf.String(), tt.want)
+			}
+		})
+	}
+
+	// Reports and binary formats do not export activities
+	if names := ActivityNames(); !slices.Equal(names, []string{"csv", "json", "ndjson"}) {
+		t.Errorf("Got formats %v writing activities, wanted csv, json and ndjson", names)
+	}
+}
@@ -0,0 +1,255 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mantas9/listings/models"
+	"mantas9/listings/stats"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func init() {
+	Register(Format{
+		Name:        "xlsx",
+		Extensions:  []string{".xlsx"},
+		Description: "Excel workbook with a summary sheet and one sheet per collection",
+		New:         func(Options) Writer { return xlsxWriter{} },
+	})
+}
+
+// Name of the summary sheet
+const xlsxSummarySheet = "Summary"
+
+// Name of the sheet of collection stats
+const xlsxStatsSheet = "Stats"
+
+// Number format of prices
+const xlsxSOLFormat = `#,##0.0000" SOL"`
+
+// Writes listings as an Excel workbook
+type xlsxWriter struct{}
+
+// Cell styles of a workbook
+type xlsxStyles struct {
+	header int // Bold header cells
+	price  int // Prices in SOL
+}
+
+func (xlsxWriter) Write(ctx context.Context, w io.Writer, listings []models.Listing) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	// Summary sheet replaces the default sheet
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSummarySheet); err != nil {
+		return err
+	}
+	if err := writeXLSXSummary(f, styles, stats.Summarize(listings)); err != nil {
+		return err
+	}
+
+	// One sheet per collection, in order of appearance
+	names := map[string]bool{strings.ToLower(xlsxSummarySheet): true} // Sheet names in use, case-insensitive like Excel
+	for _, group := range groupByCollection(listings) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		sheet := xlsxSheetName(group[0].Collection, names)
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+		if err := writeXLSXListings(f, styles, sheet, group); err != nil {
+			return err
+		}
+	}
+
+	f.SetActiveSheet(0)
+
+	return f.Write(w)
+}
+
+func (xlsxWriter) WriteStats(ctx context.Context, w io.Writer, collections []models.CollectionStats) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	// Single sheet replacing the default sheet
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxStatsSheet); err != nil {
+		return err
+	}
+
+	rows := [][]any{}
+	for _, collection := range collections {
+		rows = append(rows, []any{
+			collection.Collection,
+			excelize.Cell{StyleID: styles.price, Value: collection.FloorPrice},
+			collection.ListedCount,
+			excelize.Cell{StyleID: styles.price, Value: collection.AvgPrice24h},
+			excelize.Cell{StyleID: styles.price, Value: collection.TotalVolume},
+		})
+	}
+	if err := writeXLSXSheet(f, styles, xlsxStatsSheet, []string{"Collection", "Floor", "Listed", "Avg 24h", "Volume"}, []float64{24, 16, 10, 16, 20}, rows); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+// Creates the cell styles of a workbook
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return xlsxStyles{}, err
+	}
+
+	solFormat := xlsxSOLFormat
+	price, err := f.NewStyle(&excelize.Style{CustomNumFmt: &solFormat})
+	if err != nil {
+		return xlsxStyles{}, err
+	}
+
+	return xlsxStyles{header: header, price: price}, nil
+}
+
+// Writes count, floor, median and max price per collection
+func writeXLSXSummary(f *excelize.File, styles xlsxStyles, summaries []stats.Summary) error {
+	rows := [][]any{}
+	for _, summary := range summaries {
+		rows = append(rows, []any{
+			summary.Collection,
+			summary.Count,
+			excelize.Cell{StyleID: styles.price, Value: summary.Floor},
+			excelize.Cell{StyleID: styles.price, Value: summary.Median},
+			excelize.Cell{StyleID: styles.price, Value: summary.Max},
+		})
+	}
+
+	return writeXLSXSheet(f, styles, xlsxSummarySheet, []string{"Collection", "Listings", "Floor", "Median", "Max"}, []float64{24, 10, 16, 16, 16}, rows)
+}
+
+// Writes the listings of a single collection
+func writeXLSXListings(f *excelize.File, styles xlsxStyles, sheet string, listings []models.Listing) error {
+	rows := [][]any{}
+	for _, listing := range listings {
+		rows = append(rows, []any{
+			listing.Collection,
+			listing.Seller,
+			excelize.Cell{StyleID: styles.price, Value: listing.Price},
+			listing.Mint,
+		})
+	}
+
+	return writeXLSXSheet(f, styles, sheet, []string{"Collection", "Seller", "Price", "Mint Address"}, []float64{24, 48, 16, 48}, rows)
+}
+
+// Streams a sheet with a frozen, filterable header row
+func writeXLSXSheet(f *excelize.File, styles xlsxStyles, sheet string, header []string, widths []float64, rows [][]any) error {
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	// Column widths must be set before any row
+	for i, width := range widths {
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+
+	// Keep the header visible while scrolling
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	// Header
+	headerRow := []any{}
+	for _, name := range header {
+		headerRow = append(headerRow, name)
+	}
+	if err := sw.SetRow("A1", headerRow, excelize.RowOpts{StyleID: styles.header}); err != nil {
+		return err
+	}
+
+	// Rows
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	// Filter and sort buttons on the header
+	last, err := excelize.CoordinatesToCellName(len(header), len(rows)+1)
+	if err != nil {
+		return err
+	}
+
+	return f.AutoFilter(sheet, "A1:"+last, nil)
+}
+
+// Groups listings by collection, in the order collections first appear
+func groupByCollection(listings []models.Listing) [][]models.Listing {
+	index := map[string]int{} // Index of each collection's group
+	groups := [][]models.Listing{}
+
+	for _, listing := range listings {
+		i, ok := index[listing.Collection]
+		if !ok {
+			i = len(groups)
+			index[listing.Collection] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], listing)
+	}
+
+	return groups
+}
+
+// Returns a unique, valid sheet name for a collection, recording it in used
+func xlsxSheetName(collection string, used map[string]bool) string {
+	// Drop characters Excel does not allow
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, collection)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "collection"
+	}
+
+	// At most 31 characters, with room for a suffix
+	base := []rune(name)
+	for i := 1; ; i++ {
+		suffix := ""
+		if i > 1 {
+			suffix = fmt.Sprintf(" (%d)", i)
+		}
+
+		candidate := string(base[:min(len(base), excelize.MaxSheetNameLength-len(suffix))]) + suffix
+		if !used[strings.ToLower(candidate)] {
+			used[strings.ToLower(candidate)] = true
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"mantas9/listings/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TestXLSXWrite writes listings of several collections and checks sheets, cell values and types, styles and frozen headers
+func TestXLSXWrite(t *testing.T) {
+	input := []models.Listing{
+		{Collection: "degods", Seller: "seller1", Price: 5.5, Mint: "mint1"},
+		{Collection: "Summary", Seller: "seller2", Price: 1, Mint: "mint2"},
+		{Collection: "degods", Seller: "seller3", Price: 4.5, Mint: "mint3"},
+		{Collection: "degods", Seller: "seller4", Price: 7, Mint: "mint4"},
+	}
+
+	var buf bytes.Buffer
+	xf, _ := Lookup("xlsx")
+	if err := xf.New(Options{}).Write(context.Background(), &buf, input); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Error opening written workbook: %v", err)
+	}
+	defer f.Close()
+
+	// Summary first, then collections in order of appearance with unique names
+	if sheets, want := f.GetSheetList(), []string{"Summary", "degods", "Summary (2)"}; !reflect.DeepEqual(sheets, want) {
+		t.Fatalf("Got sheets %v, wanted %v", sheets, want)
+	}
+
+	// Test table of cells
+	var tests = []struct {
+		sheet    string
+		cell     string
+		want     string // Formatted value
+		wantType excelize.CellType
+	}{
+		{sheet: "Summary", cell: "A1", want: "Collection", wantType: excelize.CellTypeInlineString},
+		{sheet: "Summary", cell: "A2", want: "degods", wantType: excelize.CellTypeInlineString},
+		{sheet: "Summary", cell: "B2", want: "3", wantType: excelize.CellTypeUnset},
+		{sheet: "Summary", cell: "C2", want: "4.5000 SOL", wantType: excelize.CellTypeUnset},
+		{sheet: "Summary", cell: "D2", want: "5.5000 SOL", wantType: excelize.CellTypeUnset},
+		{sheet: "Summary", cell: "E2", want: "7.0000 SOL", wantType: excelize.CellTypeUnset},
+		{sheet: "Summary", cell: "A3", want: "Summary", wantType: excelize.CellTypeInlineString},
+		{sheet: "degods", cell: "C1", want: "Price", wantType: excelize.CellTypeInlineString},
+		{sheet: "degods", cell: "B4", want: "seller4", wantType: excelize.CellTypeInlineString},
+		{sheet: "degods", cell: "C4", want: "7.0000 SOL", wantType: excelize.CellTypeUnset},
+		{sheet: "Summary (2)", cell: "D2", want: "mint2", wantType: excelize.CellTypeInlineString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sheet+"!"+tt.cell, func(t *testing.T) {
+			value, err := f.GetCellValue(tt.sheet, tt.cell)
+			if err != nil || strings.TrimSpace(value) != tt.want {
+				t.Errorf("Got %q (%v), wanted %q", value, err, tt.want)
+			}
+
+			// Numbers are stored without a type attribute
+			cellType, err := f.GetCellType(tt.sheet, tt.cell)
+			if err != nil || cellType != tt.wantType {
+				t.Errorf("Got cell type %v (%v), wanted %v", cellType, err, tt.wantType)
+			}
+		})
+	}
+
+	// Every sheet has a frozen header row
+	for _, sheet := range f.GetSheetList() {
+		panes, err := f.GetPanes(sheet)
+		if err != nil || !panes.Freeze || panes.YSplit != 1 {
+			t.Errorf("Sheet %s: got panes %+v (%v), wanted a frozen header row", sheet, panes, err)
+		}
+	}
+}
+
+// TestXLSXWriteStats writes collection stats and checks the single sheet and its cells
+func TestXLSXWriteStats(t *testing.T) {
+	input := []models.CollectionStats{{Collection: "degods", FloorPrice: 5.5, ListedCount: 412, AvgPrice24h: 6, TotalVolume: 1234.5}}
+
+	var buf bytes.Buffer
+	xf, _ := Lookup("xlsx")
+	if err := xf.New(Options{}).(StatsWriter).WriteStats(context.Background(), &buf, input); err != nil { // Error check
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Error opening written workbook: %v", err)
+	}
+	defer f.Close()
+
+	if sheets, want := f.GetSheetList(), []string{"Stats"}; !reflect.DeepEqual(sheets, want) {
+		t.Fatalf("Got sheets %v, wanted %v", sheets, want)
+	}
+
+	rows, err := f.GetRows("Stats")
+	want := [][]string{{"Collection", "Floor", "Listed", "Avg 24h", "Volume"}, {"degods", "5.5000 SOL", "412", "6.0000 SOL", "1,234.5000 SOL"}}
+	if err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Got rows %q (%v), wanted %q", rows, err, want)
+	}
+}
+
+// TestXLSXSheetName calls xlsxSheetName with invalid, long and duplicate collection names
+func TestXLSXSheetName(t *testing.T) {
+	used := map[string]bool{"summary": true}
+
+	// Test table, names are generated in order
+	var tests = []struct {
+		collection string
+		want       string
+	}{
+		{collection: "degods", want: "degods"},
+		{collection: "DeGods", want: "DeGods (2)"},
+		{collection: "a/b:c", want: "a_b_c"},
+		{collection: "summary", want: "summary (2)"},
+		{collection: strings.Repeat("x", 40), want: strings.Repeat("x", 31)},
+		{collection: strings.Repeat("x", 35), want: strings.Repeat("x", 27) + " (2)"},
+		{collection: "''", want: "collection"},
+	}
+
+	for _, tt := range tests {
+		if got := xlsxSheetName(tt.collection, used); got != tt.want {
+			t.Errorf("%s: got %q, wanted %q", tt.collection, got, tt.want)
+		}
+	}
+}